@@ -0,0 +1,100 @@
+// Package baseline stores the coverage profiles of commits and resolves the
+// baseline profile to compare a branch against, using only local git.
+package baseline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxDepth is the default number of first-parent ancestors of the
+// merge base that are searched for a baseline.
+const DefaultMaxDepth = 100
+
+// ErrNoBaseline is returned if no baseline could be found.
+var ErrNoBaseline = errors.New("no baseline found")
+
+// Baseline is the profile of the nearest ancestor of a merge base for which
+// a profile was stored.
+type Baseline struct {
+	// Commit is the commit whose profile is used as baseline.
+	Commit string
+	// MergeBase is the merge base of the compared base and head.
+	MergeBase string
+	// Distance is the number of commits between Commit and MergeBase. It is
+	// zero if the profile of the merge base itself was found. Otherwise, the
+	// coverage changes of these commits are attributed to the compared branch.
+	Distance int
+	// Source describes where the profile was loaded from (e.g. a file path).
+	Source string
+	// Profile is the stored coverage profile.
+	Profile []byte
+}
+
+// resolve walks the first parents of the merge base of base and head in the
+// git repository in dir and returns the first of up to maxDepth commits for
+// which load returns a profile. The load function returns a nil profile for
+// commits without a stored profile.
+func resolve(dir, base, head string, maxDepth int, load func(commit string) (profile []byte, source string, err error)) (*Baseline, error) {
+	mergeBase, err := git(dir, nil, "merge-base", base, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine merge base: %w", err)
+	}
+
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+
+	b := &Baseline{MergeBase: strings.TrimSpace(string(mergeBase))}
+	revs, err := git(dir, nil, "rev-list", "--first-parent", "--max-count", strconv.Itoa(maxDepth), b.MergeBase)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rev := range strings.Fields(string(revs)) {
+		profile, source, err := load(rev)
+		if err != nil {
+			return nil, err
+		}
+
+		if profile != nil {
+			b.Commit, b.Distance, b.Source, b.Profile = rev, i, source, profile
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: none of the %d commits before the merge base of %s and %s has a stored profile", ErrNoBaseline, maxDepth, base, head)
+}
+
+// revParse returns the full SHA of the given commit.
+func revParse(dir, rev string) (string, error) {
+	out, err := git(dir, nil, "rev-parse", "--verify", rev+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// git runs git in dir with the given arguments and stdin and returns its output.
+func git(dir string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...) //nolint:gosec // the arguments are not interpreted by a shell
+	cmd.Dir = dir
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
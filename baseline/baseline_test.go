@@ -0,0 +1,51 @@
+package baseline
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepo creates a git repository with the following history and returns
+// the SHAs of its commits:
+//
+//	c1 - c2 - c3  (main)
+//	      \
+//	       c4     (feature)
+func newTestRepo(t *testing.T) (dir string, commits []string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir = t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	commit := func(msg string) string {
+		git("commit", "--quiet", "--allow-empty", "--message", msg)
+		return git("rev-parse", "HEAD")
+	}
+
+	git("init", "--quiet", "--initial-branch", "main")
+	commits = append(commits, commit("c1"), commit("c2"))
+	git("checkout", "--quiet", "-b", "feature")
+	commits = append(commits, "", commit("c4"))
+	git("checkout", "--quiet", "main")
+	commits[2] = commit("c3")
+
+	return dir, commits
+}
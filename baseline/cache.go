@@ -0,0 +1,81 @@
+package baseline
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Cache stores coverage profiles in a directory with one file per commit,
+// named after the full commit SHA (e.g. restored from a CI cache).
+type Cache struct {
+	// Dir is the cache directory.
+	Dir string
+	// Repo is the working directory of the git repository. If it is empty,
+	// the current working directory is used.
+	Repo string
+	// MaxDepth is the number of ancestors of the merge base that are searched
+	// by Resolve. If it is not positive, DefaultMaxDepth is used.
+	MaxDepth int
+}
+
+// Path returns the path of the cached profile of the commit with the given
+// full SHA.
+func (c *Cache) Path(sha string) string {
+	return filepath.Join(c.Dir, sha+".txt")
+}
+
+// Write stores the given profile of the commit in the cache directory. The
+// directory is created if it does not exist yet.
+func (c *Cache) Write(commit string, profile []byte) error {
+	sha, err := revParse(c.Repo, commit)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0750); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.Path(sha), profile, 0600)
+}
+
+// Read returns the cached profile of the commit. If the commit has no cached
+// profile, ErrNoBaseline is returned.
+func (c *Cache) Read(commit string) ([]byte, error) {
+	sha, err := revParse(c.Repo, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := c.read(sha)
+	if err == nil && profile == nil {
+		err = fmt.Errorf("%w: commit %s has no profile in %s", ErrNoBaseline, commit, c.Dir)
+	}
+
+	return profile, err
+}
+
+// Resolve finds the baseline to compare head against. It walks the first
+// parents of the merge base of base and head and returns the profile of the
+// nearest commit that has a cached profile. If no such commit exists within
+// MaxDepth commits, ErrNoBaseline is returned.
+func (c *Cache) Resolve(base, head string) (*Baseline, error) {
+	return resolve(c.Repo, base, head, c.MaxDepth, func(commit string) ([]byte, string, error) {
+		profile, err := c.read(commit)
+		return profile, c.Path(commit), err
+	})
+}
+
+// read returns the cached profile of the commit with the given full SHA or
+// nil if it is not cached.
+func (c *Cache) read(sha string) ([]byte, error) {
+	profile, err := os.ReadFile(c.Path(sha))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return profile, err
+}
@@ -0,0 +1,52 @@
+package baseline
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_WriteAndRead(t *testing.T) {
+	repo, commits := newTestRepo(t)
+	cache := &Cache{Dir: filepath.Join(t.TempDir(), "cache"), Repo: repo}
+
+	_, err := cache.Read("main")
+	assert.ErrorIs(t, err, ErrNoBaseline)
+
+	require.NoError(t, cache.Write("main", []byte("mode: set\n")))
+	assert.FileExists(t, filepath.Join(cache.Dir, commits[2]+".txt"))
+
+	profile, err := cache.Read(commits[2])
+	require.NoError(t, err)
+	assert.Equal(t, "mode: set\n", string(profile))
+}
+
+func TestCache_Resolve(t *testing.T) {
+	repo, commits := newTestRepo(t)
+	cache := &Cache{Dir: t.TempDir(), Repo: repo}
+
+	_, err := cache.Resolve("main", "feature")
+	assert.ErrorIs(t, err, ErrNoBaseline)
+
+	// The newer profile of c3 must not be used since it is not an ancestor of the feature branch.
+	require.NoError(t, cache.Write(commits[0], []byte("c1")))
+	require.NoError(t, cache.Write(commits[2], []byte("c3")))
+
+	b, err := cache.Resolve("main", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, &Baseline{
+		Commit:    commits[0],
+		MergeBase: commits[1],
+		Distance:  1,
+		Source:    cache.Path(commits[0]),
+		Profile:   []byte("c1"),
+	}, b)
+
+	require.NoError(t, cache.Write(commits[1], []byte("c2")))
+	b, err = cache.Resolve("main", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, commits[1], b.Commit)
+	assert.Equal(t, 0, b.Distance)
+}
@@ -0,0 +1,97 @@
+package baseline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultNotesRef is the git notes ref under which coverage profiles are
+// stored by default.
+const DefaultNotesRef = "refs/notes/coverage"
+
+// Notes stores coverage profiles as git notes so that they travel with the
+// repository (e.g. via "git push origin refs/notes/coverage") and do not
+// expire like CI artifacts.
+type Notes struct {
+	// Dir is the working directory of the git repository. If it is empty,
+	// the current working directory is used.
+	Dir string
+	// Ref is the notes ref. If it is empty, DefaultNotesRef is used.
+	Ref string
+	// MaxDepth is the number of ancestors of the merge base that are searched
+	// by Resolve. If it is not positive, DefaultMaxDepth is used.
+	MaxDepth int
+}
+
+// Write stores the given profile as note of the commit. An existing note of
+// the commit is replaced.
+func (n *Notes) Write(commit string, profile []byte) error {
+	_, err := git(n.Dir, profile, "notes", "--ref", n.ref(), "add", "--force", "--file", "-", commit)
+	return err
+}
+
+// Read returns the profile that is stored as note of the commit. If the
+// commit has no note, ErrNoBaseline is returned.
+func (n *Notes) Read(commit string) ([]byte, error) {
+	noted, err := n.notedCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	sha, err := revParse(n.Dir, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	if !noted[sha] {
+		return nil, fmt.Errorf("%w: commit %s has no note in %s", ErrNoBaseline, commit, n.ref())
+	}
+
+	return git(n.Dir, nil, "notes", "--ref", n.ref(), "show", sha)
+}
+
+// Resolve finds the baseline to compare head against. It walks the first
+// parents of the merge base of base and head and returns the profile of the
+// nearest commit that has a note. If no such commit exists within MaxDepth
+// commits, ErrNoBaseline is returned.
+func (n *Notes) Resolve(base, head string) (*Baseline, error) {
+	noted, err := n.notedCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	return resolve(n.Dir, base, head, n.MaxDepth, func(commit string) ([]byte, string, error) {
+		if !noted[commit] {
+			return nil, "", nil
+		}
+
+		profile, err := git(n.Dir, nil, "notes", "--ref", n.ref(), "show", commit)
+		return profile, n.ref() + ":" + commit, err
+	})
+}
+
+// notedCommits returns the set of all commits that have a note.
+func (n *Notes) notedCommits() (map[string]bool, error) {
+	out, err := git(n.Dir, nil, "notes", "--ref", n.ref(), "list")
+	if err != nil {
+		return nil, err
+	}
+
+	noted := map[string]bool{}
+	for _, line := range strings.Split(string(out), "\n") {
+		// Each line has the format "<note object> <annotated commit>".
+		if fields := strings.Fields(line); len(fields) == 2 {
+			noted[fields[1]] = true
+		}
+	}
+
+	return noted, nil
+}
+
+func (n *Notes) ref() string {
+	if n.Ref == "" {
+		return DefaultNotesRef
+	}
+
+	return n.Ref
+}
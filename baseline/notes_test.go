@@ -0,0 +1,55 @@
+package baseline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotes_WriteAndRead(t *testing.T) {
+	dir, commits := newTestRepo(t)
+	notes := &Notes{Dir: dir}
+
+	_, err := notes.Read("main")
+	assert.ErrorIs(t, err, ErrNoBaseline)
+
+	require.NoError(t, notes.Write("main", []byte("mode: set\n")))
+	require.NoError(t, notes.Write(commits[2], []byte("mode: count\n")))
+
+	profile, err := notes.Read("main")
+	require.NoError(t, err)
+	assert.Equal(t, "mode: count\n", string(profile))
+}
+
+func TestNotes_Resolve(t *testing.T) {
+	dir, commits := newTestRepo(t)
+	notes := &Notes{Dir: dir, Ref: "refs/notes/test"}
+
+	_, err := notes.Resolve("main", "feature")
+	assert.ErrorIs(t, err, ErrNoBaseline)
+
+	// The note of c3 must be ignored since it is not an ancestor of the feature branch.
+	require.NoError(t, notes.Write(commits[0], []byte("c1\n")))
+	require.NoError(t, notes.Write(commits[2], []byte("c3\n")))
+
+	b, err := notes.Resolve("main", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, commits[0], b.Commit)
+	assert.Equal(t, commits[1], b.MergeBase)
+	assert.Equal(t, 1, b.Distance)
+	assert.Equal(t, "refs/notes/test:"+commits[0], b.Source)
+	assert.Equal(t, "c1\n", string(b.Profile))
+
+	// With a depth of 1, only the merge base itself is considered.
+	notes.MaxDepth = 1
+	_, err = notes.Resolve("main", "feature")
+	assert.ErrorIs(t, err, ErrNoBaseline)
+
+	require.NoError(t, notes.Write(commits[1], []byte("c2\n")))
+	b, err = notes.Resolve("main", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, commits[1], b.Commit)
+	assert.Equal(t, 0, b.Distance)
+	assert.Equal(t, "c2\n", string(b.Profile))
+}
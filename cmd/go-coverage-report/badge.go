@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/fgrosse/go-coverage-report/report"
+)
+
+const badgeUsage = `
+Usage: go-coverage-report badge [OPTIONS] <COVERAGE_FILE>
+
+Render the total coverage of COVERAGE_FILE as shields.io style SVG badge, e.g. to
+commit or publish it for your README. With -per-package, an additional badge is
+written for each package into the given directory. The file name of a package
+badge is its (trimmed) package path with slashes replaced by underscores or
+"root" for the package at the trimmed prefix itself.
+
+The badge color is selected from the -colors buckets: each bucket is a minimum
+coverage and a color (a hex value or one of brightgreen, green, yellowgreen,
+yellow, orange, red, blue and lightgrey). The bucket with the highest minimum
+that is not above the coverage is used.
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to the coverage file in the format produced by go test -coverprofile
+`
+
+func runBadge(args []string) error {
+	fs := newFlagSet("badge", badgeUsage)
+	label := fs.String("label", "coverage", "the text on the left side of the badge")
+	colors := fs.String("colors", report.FormatBadgeColors(report.DefaultBadgeColors), "comma separated color buckets in the format min:color")
+	output := fs.String("o", "", "write the badge of the total coverage to this file instead of stdout")
+	perPackage := fs.String("per-package", "", "also write one badge per package into this directory")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression")
+	trim := fs.String("trim", "", "trim a prefix from all package names")
+
+	args = parseFlags(fs, args, 1, 1)
+
+	buckets, err := report.ParseBadgeColors(*colors)
+	if err != nil {
+		return err
+	}
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	cov, err := coverage.ParseFile(args[0], excludeRegex)
+	if err != nil {
+		return fmt.Errorf("failed to parse coverage: %w", err)
+	}
+
+	if *trim != "" {
+		cov.TrimPrefix(*trim)
+	}
+
+	if *perPackage != "" {
+		if err := writePackageBadges(cov, *perPackage, *label, buckets); err != nil {
+			return fmt.Errorf("failed to write package badges: %w", err)
+		}
+	}
+
+	w, closeOutput, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(w, report.Badge(*label, cov.Percent(), buckets))
+	if err != nil {
+		_ = closeOutput()
+		return fmt.Errorf("failed to write badge: %w", err)
+	}
+
+	return closeOutput()
+}
+
+func writePackageBadges(cov *coverage.Coverage, dir, label string, buckets []report.BadgeColor) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	for name, pkg := range cov.ByPackage() {
+		filename := strings.ReplaceAll(strings.Trim(name, "/"), "/", "_")
+		if filename == "." {
+			filename = "root" // the package in the trimmed prefix itself
+		}
+
+		err := os.WriteFile(filepath.Join(dir, filename+".svg"), []byte(report.Badge(label, pkg.Percent(), buckets)), 0600)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
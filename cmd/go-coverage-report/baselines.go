@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/baseline"
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// A baselineStore stores the coverage profiles of commits and resolves the
+// baseline of a branch (see baseline.Notes and baseline.Cache).
+type baselineStore interface {
+	Write(commit string, profile []byte) error
+	Resolve(base, head string) (*baseline.Baseline, error)
+}
+
+// storeFlags registers the flags of a baselineStore in fs and returns a
+// function that creates the store after the flags were parsed.
+type storeFlags func(fs *flag.FlagSet) func() baselineStore
+
+// runBaselineCommand runs the "write" or "baseline" action of a command that
+// manages a baseline store.
+func runBaselineCommand(name, usage string, args []string, newStore storeFlags) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "write":
+			return runBaselineWrite(name+" write", usage, args[1:], newStore)
+		case "baseline":
+			return runBaselineResolve(name+" baseline", usage, args[1:], newStore)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(usage))
+	os.Exit(1)
+	return nil
+}
+
+func runBaselineWrite(name, usage string, args []string, newStore storeFlags) error {
+	fs := newFlagSet(name, usage)
+	store := newStore(fs)
+	commit := fs.String("commit", "HEAD", "the commit of the profile")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression from the stored profile")
+	trim := fs.String("trim", "", "trim a prefix from all file names of the stored profile")
+
+	args = parseFlags(fs, args, 1, 1)
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	cov, err := coverage.ParseFile(args[0], excludeRegex)
+	if err != nil {
+		return fmt.Errorf("failed to parse coverage: %w", err)
+	}
+
+	if *trim != "" {
+		cov.TrimPrefix(*trim)
+	}
+
+	profile := new(bytes.Buffer)
+	if err := cov.Write(profile); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	if err := store().Write(*commit, profile.Bytes()); err != nil {
+		return fmt.Errorf("failed to store profile: %w", err)
+	}
+
+	return nil
+}
+
+func runBaselineResolve(name, usage string, args []string, newStore storeFlags) error {
+	fs := newFlagSet(name, usage)
+	store := newStore(fs)
+	base := fs.String("base", "origin/main", "the branch that is merged into")
+	head := fs.String("head", "HEAD", "the commit or branch that is compared against the baseline")
+	output := fs.String("o", "", "write the baseline profile to this file instead of stdout")
+
+	parseFlags(fs, args, 0, 0)
+
+	b, err := store().Resolve(*base, *head)
+	if err != nil {
+		return err
+	}
+
+	logBaseline(b)
+
+	w, closeOutput, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b.Profile)
+	if err != nil {
+		_ = closeOutput()
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+
+	return closeOutput()
+}
+
+func logBaseline(b *baseline.Baseline) {
+	if b.Distance == 0 {
+		log.Printf("Using baseline coverage of merge base %s", b.Commit)
+		return
+	}
+
+	log.Printf("Using baseline coverage of commit %s which is %d commit(s) older than the merge base %s", b.Commit, b.Distance, b.MergeBase)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/fgrosse/go-coverage-report/baseline"
+)
+
+const cacheUsage = `
+Usage: go-coverage-report cache write [OPTIONS] <COVERAGE_FILE>
+       go-coverage-report cache baseline [OPTIONS]
+
+Store coverage profiles in a cache directory with one file per commit SHA and
+read them back as baseline for future comparisons. The directory can for example
+be persisted with the cache of your CI system.
+
+"cache write" stores the normalized COVERAGE_FILE as profile of a commit (HEAD by
+default).
+
+"cache baseline" determines the merge base of -base and -head and walks its first
+parents until it finds a commit with a cached profile. Comparing against the merge
+base instead of the latest commit of the base branch ensures that changes that were
+merged into the base branch in the meantime do not show up as changes of your branch.
+The profile is written to stdout (or -o) and can be used as OLD_COVERAGE_FILE of the
+diff command. If the profile of the merge base itself is not cached, a warning with
+the number of commits between the baseline and the merge base is printed.
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to the coverage file in the format produced by go test -coverprofile
+`
+
+func runCache(args []string) error {
+	return runBaselineCommand("cache", cacheUsage, args, func(fs *flag.FlagSet) func() baselineStore {
+		dir := fs.String("dir", ".coverage-cache", "the cache directory")
+		maxDepth := fs.Int("max-depth", baseline.DefaultMaxDepth, "maximum number of commits to search for a cached profile, starting at the merge base")
+
+		return func() baselineStore {
+			return &baseline.Cache{Dir: *dir, MaxDepth: *maxDepth}
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/fgrosse/go-coverage-report/report"
+)
+
+const checkUsage = `
+Usage: go-coverage-report check [OPTIONS] <OLD_COVERAGE_FILE> <NEW_COVERAGE_FILE> <CHANGED_FILES_FILE>
+
+Compare the OLD_COVERAGE_FILE and NEW_COVERAGE_FILE like the "diff" command but
+instead of printing a report, only verify the configured coverage gates. Each
+violation is printed to stderr and the command exits with a non-zero status
+if at least one gate failed.
+
+ARGUMENTS:
+  OLD_COVERAGE_FILE   The path to the old coverage file in the format produced by go test -coverprofile
+  NEW_COVERAGE_FILE   The path to the new coverage file in the same format as OLD_COVERAGE_FILE
+  CHANGED_FILES_FILE  The path to the file containing the list of changed files encoded as JSON string array
+`
+
+func runCheck(args []string) error {
+	fs := newFlagSet("check", checkUsage)
+	root := fs.String("root", "", "The import path of the tested repository to add as prefix to all paths of the changed files")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression from the check")
+	minCoverage := fs.Float64("min-coverage", 0, "minimum total coverage of the new profile in percent (0 disables this gate)")
+	minPackageCoverage := fs.Float64("min-package-coverage", 0, "minimum coverage of each changed package in percent (0 disables this gate)")
+	matrix := fs.Bool("matrix", false, "treat labeled profiles (label=path) as the platforms of a build matrix")
+	maxDecrease := fs.Float64("max-decrease", -1, "maximum coverage decrease of each changed package in percentage points (negative values disable this gate)")
+
+	args = parseFlags(fs, args, 3, 3)
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	rep, err := loadReport(args[0], args[1], args[2], diffOptions{
+		root:    *root,
+		exclude: excludeRegex,
+		matrix:  *matrix,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(rep.ChangedFiles) == 0 {
+		log.Println("Skipping coverage check since there are no changed files")
+		return nil
+	}
+
+	violations := rep.Check(report.Gates{
+		MinCoverage:        *minCoverage,
+		MinPackageCoverage: *minPackageCoverage,
+		MaxDecrease:        *maxDecrease,
+	})
+
+	for _, v := range violations {
+		log.Println("FAIL:", v)
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %d gate(s) violated", errCheckFailed, len(violations))
+	}
+
+	log.Println("All coverage checks passed")
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/fgrosse/go-coverage-report/report"
+)
+
+const compareUsage = `
+Usage: go-coverage-report compare [OPTIONS] <NAME=COVERAGE_FILE>...
+
+Compare the coverage of two or more named profiles side by side (e.g. of different
+Go versions or feature flags). The result is a table with one row per package and
+one column per profile which shows the coverage of each profile together with its
+delta relative to the reference profile.
+
+By default, the first profile is the reference. Use the -reference flag to select
+another one by name.
+
+ARGUMENTS:
+  NAME=COVERAGE_FILE  A name and the path to a coverage file in the format produced by go test -coverprofile
+`
+
+func runCompare(args []string) error {
+	fs := newFlagSet("compare", compareUsage)
+	reference := fs.String("reference", "", "name of the profile that all deltas are relative to (default: the first profile)")
+	trim := fs.String("trim", "", "trim a prefix in the \"Package\" column of the comparison")
+	format := fs.String("format", "markdown", "output format ('markdown' or 'json')")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression from the comparison")
+	accessible := fs.Bool("accessible", false, "render the deltas with arrows and words instead of emojis")
+
+	args = parseFlags(fs, args, 2, -1)
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	var (
+		names []string
+		covs  []*coverage.Coverage
+	)
+
+	for _, arg := range args {
+		name, path, _ := strings.Cut(arg, "=")
+		if name == "" || path == "" {
+			return fmt.Errorf("invalid profile %q: expected name=path", arg)
+		}
+
+		if slices.Contains(names, name) {
+			return fmt.Errorf("duplicate profile name %q", name)
+		}
+
+		cov, err := coverage.ParseFile(path, excludeRegex)
+		if err != nil {
+			return fmt.Errorf("failed to parse %q profile: %w", name, err)
+		}
+
+		names = append(names, name)
+		covs = append(covs, cov)
+	}
+
+	if *reference == "" {
+		*reference = names[0]
+	}
+
+	comparison, err := report.NewComparison(names, covs, *reference)
+	if err != nil {
+		return err
+	}
+
+	if *trim != "" {
+		comparison.TrimPrefix(*trim)
+	}
+
+	comparison.Accessible = *accessible
+
+	switch strings.ToLower(*format) {
+	case "markdown":
+		fmt.Fprintln(os.Stdout, comparison.Markdown())
+	case "json":
+		fmt.Fprintln(os.Stdout, comparison.JSON())
+	default:
+		return fmt.Errorf("unsupported format: %q", *format)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+const convertUsage = `
+Usage: go-coverage-report convert [OPTIONS] <COVERAGE_FILE>
+
+Convert the COVERAGE_FILE from one format into another. The output is always
+normalized: files are sorted by name, blocks by position and blocks at the same
+location are merged. You can use the -exclude and -trim flags to remove files
+and rewrite paths, e.g. to produce a cleaned profile for go tool cover -html or
+as baseline for future comparisons.
+
+The following formats are supported:
+
+  go    The text format produced by go test -coverprofile
+  json  A JSON document with all files and their blocks
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to the coverage file in the format given by -from
+`
+
+func runConvert(args []string) error {
+	fs := newFlagSet("convert", convertUsage)
+	from := fs.String("from", "go", "format of the input file ('go' or 'json')")
+	to := fs.String("to", "json", "format of the output ('go' or 'json')")
+	output := fs.String("o", "", "write the converted profile to this file instead of stdout")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression from the output")
+	trim := fs.String("trim", "", "trim a prefix from all file names")
+
+	args = parseFlags(fs, args, 1, 1)
+
+	// The output format is validated before the output file is truncated.
+	var write func(io.Writer, []*coverage.Profile) error
+	switch strings.ToLower(*to) {
+	case "go":
+		write = coverage.WriteProfiles
+	case "json":
+		write = coverage.WriteProfilesJSON
+	default:
+		return fmt.Errorf("unsupported output format: %q", *to)
+	}
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := readProfiles(args[0], *from, excludeRegex)
+	if err != nil {
+		return err
+	}
+
+	if *trim != "" {
+		cov := coverage.New(profiles)
+		cov.TrimPrefix(*trim)
+		profiles = cov.Profiles()
+	}
+
+	w, closeOutput, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	err = write(w, profiles)
+	if err != nil {
+		_ = closeOutput()
+		return err
+	}
+
+	return closeOutput()
+}
+
+func readProfiles(path, format string, exclude *regexp.Regexp) ([]*coverage.Profile, error) {
+	switch strings.ToLower(format) {
+	case "go":
+		return coverage.ParseProfiles(path, exclude)
+	case "json":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		profiles, err := coverage.ParseProfilesJSON(f)
+		if err != nil || exclude == nil {
+			return profiles, err
+		}
+
+		filtered := profiles[:0]
+		for _, p := range profiles {
+			if !exclude.MatchString(p.FileName) {
+				filtered = append(filtered, p)
+			}
+		}
+
+		return filtered, nil
+	default:
+		return nil, fmt.Errorf("unsupported input format: %q", format)
+	}
+}
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/baseline"
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/fgrosse/go-coverage-report/report"
+)
+
+const diffUsage = `
+Usage: go-coverage-report diff [OPTIONS] <OLD_COVERAGE_FILE> <NEW_COVERAGE_FILE> <CHANGED_FILES_FILE>
+       go-coverage-report diff -all [OPTIONS] <OLD_COVERAGE_FILE> <NEW_COVERAGE_FILE>
+       go-coverage-report diff -baseline-cache=DIR [OPTIONS] <NEW_COVERAGE_FILE> <CHANGED_FILES_FILE>
+
+Parse the OLD_COVERAGE_FILE and NEW_COVERAGE_FILE and compare the coverage of the
+files listed in CHANGED_FILES_FILE. The result is printed to stdout as a simple
+Markdown table with emojis indicating the coverage change per package.
+
+You can use the -root flag to add a prefix to all paths in the list of changed
+files. This is useful to map the changed files (e.g., ["foo/my_file.go"] to their
+coverage profile which uses the full package name to identify the files
+(e.g., "github.com/fgrosse/example/foo/my_file.go"). Note that currently,
+packages with a different name than their directory are not supported.
+
+You can use the -template flag to render the report with your own Go text/template
+instead of the built-in Markdown template. See the README for the available fields
+and helper functions.
+
+Instead of a single path, OLD_COVERAGE_FILE and NEW_COVERAGE_FILE can also be a
+comma separated list of labeled profiles (e.g. "unit=unit.out,e2e=e2e.out"). The
+report then shows the coverage of each label in addition to the combined coverage.
+With the -matrix flag, the labels are treated as the platforms of a build matrix
+(e.g. "linux=linux.out,windows=windows.out") instead. The profiles are merged and the
+report shows which platforms contributed coverage for each changed file.
+
+Changes of tests or test helpers often change the coverage of packages that have
+no changed files. Use the -indirect-threshold flag to list all packages whose
+coverage changed by more than the given number of percentage points in an
+additional "Indirectly impacted packages" section.
+
+With the -all flag, no CHANGED_FILES_FILE is needed. Instead, all packages and files
+in either profile are compared (e.g. for nightly comparisons between releases). They
+are sorted by their coverage change, largest decrease first, and the report starts
+with the packages with the largest gains and losses (see -top).
+
+Instead of passing OLD_COVERAGE_FILE, you can resolve the baseline of the branch
+from a cache directory (-baseline-cache) or git notes (-baseline-notes) which were
+written by the cache and notes commands. The baseline is the profile of the merge
+base of -base and -head or of its nearest ancestor with a stored profile. If it is
+older than the merge base, the report header shows by how many commits.
+
+With the -history flag, the report shows a sparkline of the coverage of each changed
+package in the last commits of a history file (see the record command) followed by
+the new coverage, together with the best and worst coverage in these commits.
+
+Large modules often consist of many small packages in a few directories. With the
+-rollup-depth flag, the impacted packages are grouped by their directory of at most
+the given number of path segments (after applying -trim). Each directory shows the
+combined coverage of all packages in it and its subdirectories, and the changed
+packages within it are listed as a tree in an expandable section below the table.
+Each nested subdirectory with more than one changed package gets its own indented
+row with the combined coverage of its packages.
+
+With the -codeowners flag, each changed file and package is attributed to its owners
+in the given CODEOWNERS file and the report contains a section per owner with its
+changed files and the combined coverage of all its files. The patterns of the
+CODEOWNERS file are matched against the file names without the -root prefix.
+
+The -components flag adds a table with the combined coverage of named groups of files
+(e.g. "api" or "storage") that are independent of the package structure. Each line of
+the components file contains the name of a component followed by one or more gitignore
+style patterns that are matched against the file names after applying -trim.
+
+The last column of the report scores each coverage change with emojis. Use the -score
+flag to select another scoring policy: "text" scores changes with "--", "-", "+" and
+"++", "grade" scores the new coverage with letter grades from A to F, "emoji:-5,5,15"
+uses custom thresholds for the skull, tada and star emojis, and "delta:-inf=bad,0=good"
+or "coverage:80=good,0=bad" use the symbol of the largest threshold that is less than or
+equal to the coverage change or the new coverage respectively. With -score, the title
+is also followed by the score of the total coverage.
+
+With the -accessible flag, the report does not contain any emojis. Coverage changes
+are shown with arrows in percentage points (e.g. "▼ 12.30 pp") and scored with words
+(e.g. "regression") so they can be read by screen readers and in plain text emails.
+
+Use -format=text to print the report as aligned tables for the terminal, including
+the per-file details. Increases and decreases are colored unless stdout is not a
+terminal or the NO_COLOR environment variable is set.
+
+Packages and files are sorted by name (or by their coverage change with -all). Use
+-sort to list them by their coverage change (largest decrease first), by their new
+coverage (lowest first), by their missed statements or by the number of added or
+removed statements (largest first). With -hide-unchanged, packages and files whose
+coverage did not change are omitted and -min-statements omits packages and files
+with fewer statements in both profiles.
+
+GitHub rejects comments that are longer than 65536 characters. With -max-length, the
+Markdown report omits details until it fits into the given number of characters: files
+with unchanged coverage are dropped, only the first ten changed test files are listed,
+the package and file tables are shortened and finally all optional sections are
+removed. Use -full-report to write the complete report to another file and
+-full-report-url to link to it from the note at the top of the truncated report.
+
+ARGUMENTS:
+  OLD_COVERAGE_FILE   The path to the old coverage file in the format produced by go test -coverprofile (not used with -baseline-cache or -baseline-notes)
+  NEW_COVERAGE_FILE   The path to the new coverage file in the same format as OLD_COVERAGE_FILE
+  CHANGED_FILES_FILE  The path to the file containing the list of changed files encoded as JSON string array (not used with -all)
+`
+
+type diffOptions struct {
+	root        string
+	trim        string
+	format      string
+	exclude     *regexp.Regexp
+	metricsFile string
+	template    string
+	profileFile string
+	matrix      bool
+	indirect    float64
+	all         bool
+	top         int
+	history     string
+	historyLen  int
+	rollup      int
+	codeOwners  string
+	components  string
+	scoring     report.ScoringPolicy
+	accessible  bool
+	sort        report.SortOrder
+	hide        bool
+	minStmts    int64
+	maxLength   int
+	fullReport  string
+	fullURL     string
+	baseline    *baseline.Baseline
+}
+
+func runDiff(args []string) error {
+	fs := newFlagSet("diff", diffUsage)
+	root := fs.String("root", "", "The import path of the tested repository to add as prefix to all paths of the changed files")
+	trim := fs.String("trim", "", "trim a prefix in the \"Impacted Packages\" column of the markdown report")
+	format := fs.String("format", "markdown", "output format ('markdown', 'json' or 'text')")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression from the report")
+	metricsFile := fs.String("metrics-file", "", "write key=value coverage metrics to this file for GitHub Actions outputs")
+	tmpl := fs.String("template", "", "render the report using the Go text/template in the given file instead of the built-in Markdown template")
+	profileFile := fs.String("write-profile", "", "write the new profile after applying -exclude and -trim to this file")
+	matrix := fs.Bool("matrix", false, "treat labeled profiles (label=path) as the platforms of a build matrix and show which platforms contributed to each file")
+	indirect := fs.Float64("indirect-threshold", 0, "also list packages without changed files whose coverage changed "+
+		"by more than this many percentage points (0 disables the section)")
+	all := fs.Bool("all", false, "compare all packages and files of both profiles instead of only the changed files")
+	top := fs.Int("top", 5, "number of packages with the largest gains and losses to list with -all (0 disables the sections)")
+	history := fs.String("history", "", "show the coverage trend of each package using the history file written by the record command")
+	historyLen := fs.Int("history-length", 10, "number of most recent commits of the -history file to show")
+	codeOwners := fs.String("codeowners", "", "show the coverage of each code owner of the changed files using this CODEOWNERS file")
+	components := fs.String("components", "", "show the coverage of the named groups of files defined in this components file")
+	score := fs.String("score", "", "scoring policy of the coverage changes "+
+		"('emoji', 'text', 'grade', 'emoji:SKULL,TADA,STAR', 'delta:MIN=SYMBOL,...' or 'coverage:MIN=SYMBOL,...')")
+	accessible := fs.Bool("accessible", false, "render coverage changes with arrows and words instead of emojis for screen readers and plain text")
+	sortOrder := fs.String("sort", "", "sort packages and files by 'name', 'delta', 'coverage', 'missed' or 'changed' statements "+
+		"(default 'name', or 'delta' with -all)")
+	hideUnchanged := fs.Bool("hide-unchanged", false, "omit packages and files whose coverage did not change")
+	minStmts := fs.Int64("min-statements", 0, "omit packages and files with fewer statements in both profiles")
+	maxLength := fs.Int("max-length", 0, "omit details of the markdown report until it has at most this many characters (0 disables the limit)")
+	fullReport := fs.String("full-report", "", "write the report in the selected -format without -max-length to this file")
+	fullURL := fs.String("full-report-url", "", "link to the full report from the note of a report that was truncated due to -max-length")
+	rollup := fs.Int("rollup-depth", 0, "group the impacted packages by their directory of at most this many path segments after -trim (0 disables the roll-up)")
+	baselineCache := fs.String("baseline-cache", "", "resolve the old profile from this cache directory (see the cache command) instead of OLD_COVERAGE_FILE")
+	baselineNotes := fs.String("baseline-notes", "", "resolve the old profile from this git notes ref (see the notes command) instead of OLD_COVERAGE_FILE")
+	base := fs.String("base", "origin/main", "the branch that is merged into when resolving the baseline")
+	head := fs.String("head", "HEAD", "the commit or branch that is compared when resolving the baseline")
+
+	args = parseFlags(fs, args, 1, 3)
+
+	if *historyLen < 0 {
+		return fmt.Errorf("invalid -history-length: must not be negative")
+	}
+
+	var store baselineStore
+	switch {
+	case *baselineCache != "" && *baselineNotes != "":
+		return fmt.Errorf("-baseline-cache and -baseline-notes cannot be used together")
+	case *baselineCache != "":
+		store = &baseline.Cache{Dir: *baselineCache}
+	case *baselineNotes != "":
+		store = &baseline.Notes{Ref: *baselineNotes}
+	}
+
+	var oldCovPath, changedFilesPath string
+	if store == nil {
+		oldCovPath, args = args[0], args[1:]
+	}
+
+	switch {
+	case len(args) == 2 && !*all:
+		changedFilesPath = args[1]
+	case len(args) != 1 || !*all:
+		fs.Usage()
+		return fmt.Errorf("unexpected number of arguments")
+	}
+
+	opts := diffOptions{
+		root:        *root,
+		trim:        *trim,
+		format:      *format,
+		metricsFile: *metricsFile,
+		template:    *tmpl,
+		profileFile: *profileFile,
+		matrix:      *matrix,
+		indirect:    *indirect,
+		all:         *all,
+		top:         *top,
+		history:     *history,
+		historyLen:  *historyLen,
+		rollup:      *rollup,
+		codeOwners:  *codeOwners,
+		components:  *components,
+		accessible:  *accessible,
+		hide:        *hideUnchanged,
+		minStmts:    *minStmts,
+		maxLength:   *maxLength,
+		fullReport:  *fullReport,
+		fullURL:     *fullURL,
+	}
+
+	var err error
+	opts.exclude, err = parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	if *score != "" {
+		opts.scoring, err = report.ParseScoringPolicy(*score)
+		if err != nil {
+			return fmt.Errorf("invalid -score: %w", err)
+		}
+	}
+
+	if *sortOrder != "" {
+		opts.sort, err = report.ParseSortOrder(*sortOrder)
+		if err != nil {
+			return fmt.Errorf("invalid -sort: %w", err)
+		}
+	}
+
+	if store != nil {
+		opts.baseline, err = store.Resolve(*base, *head)
+		if err != nil {
+			return fmt.Errorf("failed to resolve baseline: %w", err)
+		}
+		logBaseline(opts.baseline)
+		oldCovPath = opts.baseline.Source
+	}
+
+	return diff(oldCovPath, args[0], changedFilesPath, opts)
+}
+
+func diff(oldCovPath, newCovPath, changedFilesPath string, opts diffOptions) error {
+	rep, err := loadReport(oldCovPath, newCovPath, changedFilesPath, opts)
+	if err != nil {
+		return err
+	}
+
+	// The normalized profile is written even if there are no changed files
+	// since later steps of the workflow may depend on it.
+	if opts.profileFile != "" {
+		if err := writeProfile(rep.New, opts.profileFile); err != nil {
+			return fmt.Errorf("failed to write profile: %w", err)
+		}
+	}
+
+	if len(rep.ChangedFiles) == 0 && !opts.all {
+		log.Println("Skipping report since there are no changed files")
+		return nil
+	}
+
+	if opts.metricsFile != "" {
+		if err := writeMetrics(rep, opts.metricsFile); err != nil {
+			return fmt.Errorf("failed to write metrics: %w", err)
+		}
+	}
+
+	if opts.fullReport != "" {
+		if err := writeFullReport(rep, opts); err != nil {
+			return fmt.Errorf("failed to write full report: %w", err)
+		}
+	}
+
+	rep.MaxLength = opts.maxLength
+	if opts.template != "" {
+		return renderTemplate(rep, opts)
+	}
+
+	switch strings.ToLower(opts.format) {
+	case "markdown":
+		fmt.Fprintln(os.Stdout, rep.Markdown())
+	case "json":
+		fmt.Fprintln(os.Stdout, rep.JSON())
+	case "text":
+		fmt.Fprint(os.Stdout, rep.Text(useColor(os.Stdout)))
+	default:
+		return fmt.Errorf("unsupported format: %q", opts.format)
+	}
+
+	return nil
+}
+
+// loadReport parses the old and new coverage profiles and the changed files
+// and creates a report. With opts.all, the changed files are ignored and all
+// files are compared.
+func loadReport(oldCovPath, newCovPath, changedFilesPath string, opts diffOptions) (*report.Report, error) {
+	var (
+		oldCov     *coverage.Coverage
+		oldLabeled []labeledCoverage
+		err        error
+	)
+
+	if opts.baseline != nil {
+		oldCov, err = coverage.Parse(bytes.NewReader(opts.baseline.Profile), opts.exclude)
+	} else {
+		oldCov, oldLabeled, err = parseCoverageArg(oldCovPath, opts.exclude, opts.matrix)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old coverage: %w", err)
+	}
+
+	newCov, newLabeled, err := parseCoverageArg(newCovPath, opts.exclude, opts.matrix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new coverage: %w", err)
+	}
+
+	var rep *report.Report
+	if opts.all {
+		rep = report.NewFull(oldCov, newCov)
+		rep.TopMovers = opts.top
+	} else {
+		changedFiles, err := report.ParseChangedFiles(changedFilesPath, opts.root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load changed files: %w", err)
+		}
+
+		rep = report.New(oldCov, newCov, changedFiles)
+	}
+
+	rep.IndirectThreshold = opts.indirect
+	rep.RollupDepth = opts.rollup
+	rep.Scoring = opts.scoring
+	rep.Accessible = opts.accessible
+	rep.Sort = opts.sort
+	rep.HideUnchanged = opts.hide
+	rep.MinStatements = opts.minStmts
+
+	if opts.codeOwners != "" {
+		rep.CodeOwners, err = report.ParseCodeOwnersFile(opts.codeOwners)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load code owners: %w", err)
+		}
+		rep.CodeOwners.Root = opts.root
+	}
+
+	if opts.components != "" {
+		rep.Components, err = report.ParseComponentsFile(opts.components)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load components: %w", err)
+		}
+	}
+
+	if opts.history != "" {
+		history, err := report.ParseHistoryFile(opts.history)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load history: %w", err)
+		}
+		rep.History = report.LastHistory(history, opts.historyLen)
+	}
+	rep.Metadata = report.Metadata{
+		Version:          version,
+		OldProfile:       oldCovPath,
+		NewProfile:       newCovPath,
+		ChangedFilesFile: changedFilesPath,
+		Root:             opts.root,
+		Trim:             opts.trim,
+		FullReportURL:    opts.fullURL,
+	}
+
+	if b := opts.baseline; b != nil {
+		rep.Metadata.BaselineCommit = b.Commit
+		rep.Metadata.MergeBase = b.MergeBase
+		rep.Metadata.BaselineDistance = b.Distance
+	}
+
+	addLabels(rep, oldLabeled, newLabeled)
+
+	if opts.trim != "" {
+		rep.TrimPrefix(opts.trim)
+	}
+
+	return rep, nil
+}
+
+// addLabels adds the labeled profiles of both sides to the report. Labels are
+// added in the order of the new profiles followed by labels that only exist
+// in the old profiles.
+func addLabels(rep *report.Report, oldLabeled, newLabeled []labeledCoverage) {
+	find := func(list []labeledCoverage, label string) *coverage.Coverage {
+		for _, l := range list {
+			if l.label == label {
+				return l.cov
+			}
+		}
+		return nil
+	}
+
+	for _, l := range newLabeled {
+		rep.AddLabel(l.label, find(oldLabeled, l.label), l.cov)
+	}
+
+	for _, l := range oldLabeled {
+		if find(newLabeled, l.label) == nil {
+			rep.AddLabel(l.label, l.cov, nil)
+		}
+	}
+}
+
+func writeMetrics(rep *report.Report, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = rep.WriteMetrics(f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeProfile(cov *coverage.Coverage, path string) error {
+	w, closeOutput, err := openOutput(path)
+	if err != nil {
+		return err
+	}
+
+	err = cov.Write(w)
+	if err != nil {
+		_ = closeOutput()
+		return err
+	}
+
+	return closeOutput()
+}
+
+// writeFullReport writes the report without truncation in the selected
+// format (or using the custom template) to opts.fullReport.
+func writeFullReport(rep *report.Report, opts diffOptions) error {
+	var out string
+	switch {
+	case opts.template != "":
+		tmpl, err := report.ParseTemplate(opts.template)
+		if err != nil {
+			return fmt.Errorf("failed to parse template: %w", err)
+		}
+
+		out, err = rep.Template(tmpl)
+		if err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
+		out += "\n"
+	case strings.EqualFold(opts.format, "json"):
+		out = rep.JSON() + "\n"
+	case strings.EqualFold(opts.format, "text"):
+		out = rep.Text(false)
+	default:
+		out = rep.Markdown() + "\n"
+	}
+
+	return os.WriteFile(opts.fullReport, []byte(out), 0600)
+}
+
+func renderTemplate(rep *report.Report, opts diffOptions) error {
+	if f := strings.ToLower(opts.format); f != "markdown" {
+		return fmt.Errorf("-template cannot be used with format %q", opts.format)
+	}
+
+	tmpl, err := report.ParseTemplate(opts.template)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	out, err := rep.Template(tmpl)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+
+	fmt.Fprintln(os.Stdout, out)
+	return nil
+}
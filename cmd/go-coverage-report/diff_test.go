@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes a file with the given content into dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestDiff_NoChangedFiles_WritesProfile(t *testing.T) {
+	dir := t.TempDir()
+	oldCov := writeFile(t, dir, "old.txt", "mode: set\nexample.com/repo/a/a.go:1.1,2.2 1 0\n")
+	newCov := writeFile(t, dir, "new.txt", "mode: set\nexample.com/repo/a/a.go:1.1,2.2 1 1\nexample.com/repo/b/b.go:1.1,2.2 1 0\n")
+	changedFiles := writeFile(t, dir, "changed.json", "[]")
+
+	opts := diffOptions{
+		format:      "markdown",
+		trim:        "example.com/repo",
+		profileFile: filepath.Join(dir, "profile.txt"),
+		metricsFile: filepath.Join(dir, "metrics.txt"),
+	}
+	require.NoError(t, diff(oldCov, newCov, changedFiles, opts))
+
+	profile, err := os.ReadFile(opts.profileFile)
+	require.NoError(t, err)
+	assert.Equal(t, "mode: set\na/a.go:1.1,2.2 1 1\nb/b.go:1.1,2.2 1 0\n", string(profile))
+
+	assert.NoFileExists(t, opts.metricsFile)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// labeledCoverage is the coverage of a profile that was passed as label=path.
+type labeledCoverage struct {
+	label string
+	cov   *coverage.Coverage
+}
+
+// parseCoverageArg parses a coverage file argument which is either a single
+// path or a comma separated list of label=path pairs (e.g. "unit=unit.out,e2e=e2e.out").
+// In the latter case the returned coverage is the union of all labeled profiles.
+// If matrix is true, the labels are the platforms of a build matrix which are
+// attributed to the files of the returned coverage instead of being returned
+// as labeled coverage.
+func parseCoverageArg(arg string, exclude *regexp.Regexp, matrix bool) (*coverage.Coverage, []labeledCoverage, error) {
+	if !isLabeledArg(arg) {
+		cov, err := coverage.ParseFile(arg, exclude)
+		return cov, nil, err
+	}
+
+	if matrix {
+		cov, err := parseMatrixArg(arg, exclude)
+		return cov, nil, err
+	}
+
+	var (
+		combined = coverage.New(nil)
+		labeled  []labeledCoverage
+	)
+
+	for _, part := range strings.Split(arg, ",") {
+		label, path, _ := strings.Cut(part, "=")
+		if label == "" || path == "" {
+			return nil, nil, fmt.Errorf("invalid labeled profile %q: expected label=path", part)
+		}
+
+		for _, l := range labeled {
+			if l.label == label {
+				return nil, nil, fmt.Errorf("duplicate label %q", label)
+			}
+		}
+
+		cov, err := coverage.ParseFile(path, exclude)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %q profile: %w", label, err)
+		}
+
+		combined, err = combined.Union(cov)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		labeled = append(labeled, labeledCoverage{label: label, cov: cov})
+	}
+
+	return combined, labeled, nil
+}
+
+// parseMatrixArg parses a comma separated list of platform=path pairs and
+// returns the union of all profiles with the platforms attributed to each file.
+func parseMatrixArg(arg string, exclude *regexp.Regexp) (*coverage.Coverage, error) {
+	var profiles []*coverage.Profile
+	for _, part := range strings.Split(arg, ",") {
+		platform, path, _ := strings.Cut(part, "=")
+		if platform == "" || path == "" {
+			return nil, fmt.Errorf("invalid platform profile %q: expected platform=path", part)
+		}
+
+		pp, err := coverage.ParseProfiles(path, exclude)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q profile: %w", platform, err)
+		}
+
+		for _, p := range pp {
+			p.Platforms = []string{platform}
+		}
+
+		profiles = append(profiles, pp...)
+	}
+
+	return coverage.NewUnion(profiles)
+}
+
+func isLabeledArg(arg string) bool {
+	for _, part := range strings.Split(arg, ",") {
+		if !strings.Contains(part, "=") {
+			return false
+		}
+	}
+
+	return true
+}
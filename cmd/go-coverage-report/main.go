@@ -1,7 +1,9 @@
 // Package main implements the go-coverage-report command line tool which compares two Go coverage profiles and generates a report about the coverage changes for a list of changed files.
+// The actual parsing and reporting is implemented in the coverage and report packages.
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -12,125 +14,161 @@ import (
 )
 
 var usage = strings.TrimSpace(fmt.Sprintf(`
-Usage: %s [OPTIONS] <OLD_COVERAGE_FILE> <NEW_COVERAGE_FILE> <CHANGED_FILES_FILE>
-
-Parse the OLD_COVERAGE_FILE and NEW_COVERAGE_FILE and compare the coverage of the
-files listed in CHANGED_FILES_FILE. The result is printed to stdout as a simple
-Markdown table with emojis indicating the coverage change per package.
-
-You can use the -root flag to add a prefix to all paths in the list of changed
-files. This is useful to map the changed files (e.g., ["foo/my_file.go"] to their
-coverage profile which uses the full package name to identify the files
-(e.g., "github.com/fgrosse/example/foo/my_file.go"). Note that currently,
-packages with a different name than their directory are not supported.
-
-ARGUMENTS:
-  OLD_COVERAGE_FILE   The path to the old coverage file in the format produced by go test -coverprofile
-  NEW_COVERAGE_FILE   The path to the new coverage file in the same format as OLD_COVERAGE_FILE
-  CHANGED_FILES_FILE  The path to the file containing the list of changed files encoded as JSON string array
-
-OPTIONS:
-`, filepath.Base(os.Args[0])))
-
-type options struct {
-	root        string
-	trim        string
-	format      string
-	exclude     *regexp.Regexp
-	metricsFile string
+Usage: %[1]s <COMMAND> [OPTIONS] [ARGUMENTS]
+       %[1]s [OPTIONS] <OLD_COVERAGE_FILE> <NEW_COVERAGE_FILE> <CHANGED_FILES_FILE>
+
+The go-coverage-report tool parses Go coverage profiles as produced by
+"go test -coverprofile" to compare, combine and check them.
+
+If no command is given, the "diff" command is executed.
+
+COMMANDS:
+%[2]s
+Run "%[1]s <COMMAND> -h" for more information about a command.
+`, filepath.Base(os.Args[0]), commandList()))
+
+// version is set at build time via -ldflags (e.g. by goreleaser).
+var version = "dev"
+
+// A command is a subcommand of the go-coverage-report CLI.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{name: "diff", summary: "Compare the coverage of the changed files in two profiles (default)", run: runDiff},
+	{name: "merge", summary: "Combine multiple profiles into a single profile", run: runMerge},
+	{name: "summary", summary: "Print the total coverage of a single profile by package", run: runSummary},
+	{name: "check", summary: "Verify coverage gates and exit with a non-zero status on violations", run: runCheck},
+	{name: "convert", summary: "Convert a profile between the supported formats", run: runConvert},
+	{name: "setop", summary: "Combine profiles via union, intersection or difference of their covered statements", run: runSetop},
+	{name: "record", summary: "Append the coverage of a profile to a history file", run: runRecord},
+	{name: "notes", summary: "Store profiles in git notes and resolve the baseline of a branch", run: runNotes},
+	{name: "cache", summary: "Store profiles in a cache directory and resolve the baseline of a branch", run: runCache},
+	{name: "ratchet", summary: "Check or update the per-package minimum coverage in a ratchet file", run: runRatchet},
+	{name: "badge", summary: "Render the coverage as SVG badge", run: runBadge},
+	{name: "treemap", summary: "Render the packages as SVG treemap sized by statements and colored by coverage", run: runTreemap},
+	{name: "compare", summary: "Compare the coverage of two or more named profiles side by side", run: runCompare},
 }
 
 func main() {
 	log.SetFlags(0)
 
-	flag.Usage = func() {
-		fmt.Fprintln(os.Stderr, usage)
-		flag.PrintDefaults()
-	}
-
-	flag.String("root", "", "The import path of the tested repository to add as prefix to all paths of the changed files")
-	flag.String("trim", "", "trim a prefix in the \"Impacted Packages\" column of the markdown report")
-	flag.String("format", "markdown", "output format (currently only 'markdown' is supported)")
-	flag.String("exclude", "", "exclude files matching the given regular expression from the report")
-	flag.String("metrics-file", "", "write key=value coverage metrics to this file for GitHub Actions outputs")
-
-	err := run(programArgs())
+	err := dispatch(os.Args[1:])
 	if err != nil {
 		log.Fatalln("ERROR:", err)
 	}
 }
 
-func programArgs() (oldCov, newCov, changedFile string, opts options) {
-	flag.Parse()
-
-	args := flag.Args()
-	if len(args) != 3 {
-		if len(args) > 0 {
-			log.Printf("ERROR: Expected exactly 3 arguments but got %d\n\n", len(args))
-		}
-		flag.Usage()
+func dispatch(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
 		os.Exit(1)
 	}
 
-	opts = options{
-		root:        flag.Lookup("root").Value.String(),
-		trim:        flag.Lookup("trim").Value.String(),
-		format:      flag.Lookup("format").Value.String(),
-		metricsFile: flag.Lookup("metrics-file").Value.String(),
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Fprintln(os.Stderr, usage)
+		return nil
 	}
 
-	if s := flag.Lookup("exclude").Value.String(); s != "" {
-		exclude, err := regexp.Compile(s)
-		if err != nil {
-			log.Printf("ERROR: -exclude %q is not a valid regular expression: %v\n", s, err)
-			os.Exit(1)
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
 		}
-		opts.exclude = exclude
 	}
 
-	return args[0], args[1], args[2], opts
+	// Keep supporting the original invocation without a command which is
+	// used by the GitHub Action.
+	return runDiff(args)
 }
 
-func run(oldCovPath, newCovPath, changedFilesPath string, opts options) error {
-	oldCov, err := ParseCoverage(oldCovPath, opts.exclude)
-	if err != nil {
-		return fmt.Errorf("failed to parse old coverage: %w", err)
+func commandList() string {
+	list := new(strings.Builder)
+	for _, cmd := range commands {
+		fmt.Fprintf(list, "  %-9s %s\n", cmd.name, cmd.summary)
 	}
 
-	newCov, err := ParseCoverage(newCovPath, opts.exclude)
-	if err != nil {
-		return fmt.Errorf("failed to parse new coverage: %w", err)
+	return list.String()
+}
+
+// newFlagSet creates the flag set of a command which prints the given usage
+// message together with the defaults of all flags.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), strings.TrimSpace(usage))
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "OPTIONS:")
+		fs.PrintDefaults()
 	}
 
-	changedFiles, err := ParseChangedFiles(changedFilesPath, opts.root)
+	return fs
+}
+
+// parseFlags parses the flags of a command and verifies that the number of
+// remaining arguments is between minArgs and maxArgs (negative means no limit).
+// If the number of arguments is invalid, the usage is printed and the program exits.
+func parseFlags(fs *flag.FlagSet, args []string, minArgs, maxArgs int) []string {
+	_ = fs.Parse(args) // the flag set exits on errors
+
+	n := fs.NArg()
+	if n < minArgs || (maxArgs >= 0 && n > maxArgs) {
+		if n > 0 {
+			log.Printf("ERROR: Unexpected number of arguments: %d\n\n", n)
+		}
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	return fs.Args()
+}
+
+func parseExclude(s string) (*regexp.Regexp, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	exclude, err := regexp.Compile(s)
 	if err != nil {
-		return fmt.Errorf("failed to load changed files: %w", err)
+		return nil, fmt.Errorf("-exclude %q is not a valid regular expression: %w", s, err)
 	}
 
-	if len(changedFiles) == 0 {
-		log.Println("Skipping report since there are no changed files")
-		return nil
+	return exclude, nil
+}
+
+// openOutput returns a writer for the given output path. If path is empty
+// or "-", the output is written to stdout.
+func openOutput(path string) (w *os.File, closeFn func() error, err error) {
+	if path == "" || path == "-" {
+		return os.Stdout, func() error { return nil }, nil
 	}
 
-	report := NewReport(oldCov, newCov, changedFiles)
-	if opts.trim != "" {
-		report.TrimPrefix(opts.trim)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if opts.metricsFile != "" {
-		if err := report.WriteMetrics(opts.metricsFile); err != nil {
-			return fmt.Errorf("failed to write metrics: %w", err)
-		}
+	return f, f.Close, nil
+}
+
+// useColor returns true if ANSI colors should be written to f, i.e. if it is
+// a terminal and colors are not disabled via NO_COLOR (see https://no-color.org).
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
 	}
 
-	switch strings.ToLower(opts.format) {
-	case "markdown":
-		fmt.Fprintln(os.Stdout, report.Markdown())
-	case "json":
-		fmt.Fprintln(os.Stdout, report.JSON())
-	default:
-		return fmt.Errorf("unsupported format: %q", opts.format)
+	info, err := f.Stat()
+	if err != nil {
+		return false
 	}
 
-	return nil
+	return info.Mode()&os.ModeCharDevice != 0
 }
+
+// errCheckFailed is returned by commands that ran successfully but whose
+// result should lead to a non-zero exit code.
+var errCheckFailed = errors.New("coverage check failed")
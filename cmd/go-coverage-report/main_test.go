@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runMainEnv is set when the test binary is executed by runCLI to run the
+// main function instead of the tests.
+const runMainEnv = "GO_COVERAGE_REPORT_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runCLI runs the command line tool with the given arguments in a separate
+// process since invalid arguments make it exit. It returns the output and
+// the exit code.
+func runCLI(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+
+	var outBuf, errBuf bytes.Buffer
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runMainEnv+"=1", "NO_COLOR=1")
+	cmd.Stdout, cmd.Stderr = &outBuf, &errBuf
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else {
+		require.NoError(t, err)
+	}
+
+	return outBuf.String(), errBuf.String(), code
+}
+
+const (
+	oldCoverage  = "../../report/testdata/01-old-coverage.txt"
+	newCoverage  = "../../report/testdata/01-new-coverage.txt"
+	changedFiles = "../../report/testdata/01-changed-files.json"
+)
+
+func TestDispatch(t *testing.T) {
+	diffOutput, _, code := runCLI(t, "diff", "-root=github.com/fgrosse/prioqueue", oldCoverage, newCoverage, changedFiles)
+	require.Equal(t, 0, code)
+	require.Contains(t, diffOutput, "### Merging this branch will")
+
+	cases := map[string]struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		"no arguments": {
+			code:   1,
+			stderr: "<COMMAND> [OPTIONS] [ARGUMENTS]",
+		},
+		"help": {
+			args:   []string{"help"},
+			stderr: "COMMANDS:",
+		},
+		"help flag": {
+			args:   []string{"-h"},
+			stderr: "COMMANDS:",
+		},
+		"command help": {
+			args:   []string{"summary", "-h"},
+			stderr: "Usage: go-coverage-report summary",
+		},
+		"diff without command": {
+			args:   []string{"-root=github.com/fgrosse/prioqueue", oldCoverage, newCoverage, changedFiles},
+			stdout: diffOutput,
+		},
+		"command": {
+			args:   []string{"summary", newCoverage},
+			stdout: "github.com/fgrosse/prioqueue",
+		},
+		"unknown command": {
+			args:   []string{"unknown"},
+			code:   1,
+			stderr: "ERROR: unexpected number of arguments",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stdout, stderr, code := runCLI(t, c.args...)
+			assert.Equal(t, c.code, code, stderr)
+			assert.Contains(t, stdout, c.stdout)
+			assert.Contains(t, stderr, c.stderr)
+		})
+	}
+}
+
+func TestArgumentValidation(t *testing.T) {
+	dir := t.TempDir()
+	output := writeFile(t, dir, "output.json", "existing output")
+
+	cases := map[string]struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		"diff without arguments":            {args: []string{"diff"}, code: 1, stderr: "Usage: go-coverage-report diff"},
+		"diff with too many arguments":      {args: []string{"diff", "a", "b", "c", "d"}, code: 1, stderr: "Unexpected number of arguments: 4"},
+		"diff without changed files":        {args: []string{"diff", oldCoverage, newCoverage}, code: 1, stderr: "ERROR: unexpected number of arguments"},
+		"diff -all with changed files":      {args: []string{"diff", "-all", oldCoverage, newCoverage, changedFiles}, code: 1, stderr: "ERROR: unexpected number of arguments"},
+		"diff -all":                         {args: []string{"diff", "-all", oldCoverage, newCoverage}},
+		"diff with unknown flag":            {args: []string{"diff", "-unknown", oldCoverage, newCoverage, changedFiles}, code: 2, stderr: "flag provided but not defined: -unknown"},
+		"diff with negative history length": {args: []string{"diff", "-history-length=-1", oldCoverage, newCoverage, changedFiles}, code: 1, stderr: "invalid -history-length"},
+		"diff with invalid sort":            {args: []string{"diff", "-sort=size", oldCoverage, newCoverage, changedFiles}, code: 1, stderr: "invalid -sort"},
+		"diff with invalid score":           {args: []string{"diff", "-score=unknown", oldCoverage, newCoverage, changedFiles}, code: 1, stderr: "invalid -score"},
+		"diff with invalid exclude":         {args: []string{"diff", "-exclude=(", oldCoverage, newCoverage, changedFiles}, code: 1, stderr: "is not a valid regular expression"},
+		"diff with invalid format":          {args: []string{"diff", "-format=xml", oldCoverage, newCoverage, changedFiles}, code: 1, stderr: `unsupported format: "xml"`},
+		"diff with two baseline stores": {
+			args: []string{"diff", "-baseline-cache=cache", "-baseline-notes=refs/notes/coverage", newCoverage, changedFiles},
+			code: 1, stderr: "-baseline-cache and -baseline-notes cannot be used together",
+		},
+		"merge without arguments":         {args: []string{"merge"}, code: 1, stderr: "Usage: go-coverage-report merge"},
+		"summary with too many arguments": {args: []string{"summary", oldCoverage, newCoverage}, code: 1, stderr: "Unexpected number of arguments: 2"},
+		"summary with invalid format":     {args: []string{"summary", "-format=xml", newCoverage}, code: 1, stderr: `unsupported format: "xml"`},
+		"check with too few arguments":    {args: []string{"check", oldCoverage, newCoverage}, code: 1, stderr: "Unexpected number of arguments: 2"},
+		"convert without arguments":       {args: []string{"convert"}, code: 1, stderr: "Usage: go-coverage-report convert"},
+		"convert with invalid input":      {args: []string{"convert", "-from=xml", newCoverage}, code: 1, stderr: `unsupported input format: "xml"`},
+		"convert with invalid output":     {args: []string{"convert", "-to=xml", "-o=" + output, newCoverage}, code: 1, stderr: `unsupported output format: "xml"`},
+		"setop with one argument":         {args: []string{"setop", newCoverage}, code: 1, stderr: "Unexpected number of arguments: 1"},
+		"setop with invalid operation":    {args: []string{"setop", "-op=xor", oldCoverage, newCoverage}, code: 1, stderr: `unsupported set operation: "xor"`},
+		"record without commit":           {args: []string{"record", "-commit=", "-history=" + filepath.Join(dir, "history.jsonl"), newCoverage}, code: 1, stderr: "missing commit"},
+		"notes without action":            {args: []string{"notes"}, code: 1, stderr: "Usage: go-coverage-report notes"},
+		"cache without action":            {args: []string{"cache"}, code: 1, stderr: "Usage: go-coverage-report cache"},
+		"cache baseline with arguments":   {args: []string{"cache", "baseline", newCoverage}, code: 1, stderr: "Unexpected number of arguments: 1"},
+		"ratchet without action":          {args: []string{"ratchet"}, code: 1, stderr: "Usage: go-coverage-report ratchet"},
+		"ratchet check without arguments": {args: []string{"ratchet", "check"}, code: 1, stderr: "Usage: go-coverage-report ratchet"},
+		"badge with invalid colors":       {args: []string{"badge", "-colors=90:pink", newCoverage}, code: 1, stderr: `unknown color "pink"`},
+		"treemap without arguments":       {args: []string{"treemap"}, code: 1, stderr: "Usage: go-coverage-report treemap"},
+		"compare with one profile":        {args: []string{"compare", "old=" + oldCoverage}, code: 1, stderr: "Unexpected number of arguments: 1"},
+		"compare without profile names":   {args: []string{"compare", oldCoverage, newCoverage}, code: 1, stderr: "expected name=path"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, stderr, code := runCLI(t, c.args...)
+			assert.Equal(t, c.code, code, stderr)
+			assert.Contains(t, stderr, c.stderr)
+		})
+	}
+
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "existing output", string(content), "an invalid -to must not truncate the output")
+}
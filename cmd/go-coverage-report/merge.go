@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+const mergeUsage = `
+Usage: go-coverage-report merge [OPTIONS] <COVERAGE_FILE>...
+
+Combine one or more coverage profiles (e.g. of multiple test runs) into a single
+profile. Blocks at the same location are merged by adding their counts (or, in
+"set" mode, by using the logical OR). All profiles must use the same mode.
+
+The merged profile is written in the format of go test -coverprofile.
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to a coverage file in the format produced by go test -coverprofile
+`
+
+func runMerge(args []string) error {
+	fs := newFlagSet("merge", mergeUsage)
+	output := fs.String("o", "", "write the merged profile to this file instead of stdout")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression from the merged profile")
+
+	args = parseFlags(fs, args, 1, -1)
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	var profileLists [][]*coverage.Profile
+	for _, path := range args {
+		profiles, err := coverage.ParseProfiles(path, excludeRegex)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		profileLists = append(profileLists, profiles)
+	}
+
+	merged, err := coverage.Merge(profileLists...)
+	if err != nil {
+		return err
+	}
+
+	w, closeOutput, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	err = coverage.WriteProfiles(w, merged)
+	if err != nil {
+		_ = closeOutput()
+		return fmt.Errorf("failed to write merged profile: %w", err)
+	}
+
+	return closeOutput()
+}
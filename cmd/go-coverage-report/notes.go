@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/fgrosse/go-coverage-report/baseline"
+)
+
+const notesUsage = `
+Usage: go-coverage-report notes write [OPTIONS] <COVERAGE_FILE>
+       go-coverage-report notes baseline [OPTIONS]
+
+Store coverage profiles in git notes and read them back as baseline for future
+comparisons. Unlike CI artifacts, git notes do not expire and work with any CI
+system. All operations use the local git repository in the current directory.
+
+"notes write" stores the normalized COVERAGE_FILE as note of a commit (HEAD by
+default). Push the notes to make them available to other clones:
+
+  git push origin refs/notes/coverage
+
+"notes baseline" determines the merge base of -base and -head and walks its
+first parents until it finds a commit with a note. The stored profile is written
+to stdout (or -o) and can be used as OLD_COVERAGE_FILE of the diff command.
+Fetch the notes before resolving the baseline:
+
+  git fetch origin refs/notes/coverage:refs/notes/coverage
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to the coverage file in the format produced by go test -coverprofile
+`
+
+func runNotes(args []string) error {
+	return runBaselineCommand("notes", notesUsage, args, func(fs *flag.FlagSet) func() baselineStore {
+		ref := fs.String("ref", baseline.DefaultNotesRef, "the git notes ref that stores the profiles")
+		maxDepth := fs.Int("max-depth", baseline.DefaultMaxDepth, "maximum number of commits to search for a note, starting at the merge base")
+
+		return func() baselineStore {
+			return &baseline.Notes{Ref: *ref, MaxDepth: *maxDepth}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/fgrosse/go-coverage-report/report"
+)
+
+const ratchetUsage = `
+Usage: go-coverage-report ratchet check [OPTIONS] <COVERAGE_FILE>
+       go-coverage-report ratchet update [OPTIONS] <COVERAGE_FILE>
+
+Ensure that the coverage of each package only ever goes up using a ratchet file
+that is committed to the repository. The ratchet file lists the minimum coverage
+of each package, one package per line.
+
+"ratchet check" fails if the coverage of any package in COVERAGE_FILE dropped
+below its ratchet value or if a package of the ratchet file is missing from
+COVERAGE_FILE, e.g. because it was excluded or its tests stopped running. Remove
+the packages you deleted from the ratchet file by hand. Packages without a
+ratchet value are ignored.
+
+"ratchet update" raises the ratchet value of each package whose coverage improved
+and adds all new packages. Values are never lowered. The file is written sorted
+by package name with two decimal places so that changes are easy to review.
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to the coverage file in the format produced by go test -coverprofile
+`
+
+func runRatchet(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "check", "update":
+			return runRatchetMode(args[0], args[1:])
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(ratchetUsage))
+	os.Exit(1)
+	return nil
+}
+
+func runRatchetMode(mode string, args []string) error {
+	fs := newFlagSet("ratchet "+mode, ratchetUsage)
+	file := fs.String("file", ".coverage-ratchet", "the ratchet file")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression")
+	trim := fs.String("trim", "", "trim a prefix from all package names")
+
+	args = parseFlags(fs, args, 1, 1)
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	cov, err := coverage.ParseFile(args[0], excludeRegex)
+	if err != nil {
+		return fmt.Errorf("failed to parse coverage: %w", err)
+	}
+
+	if *trim != "" {
+		cov.TrimPrefix(*trim)
+	}
+
+	ratchet, err := loadRatchet(*file, mode == "update")
+	if err != nil {
+		return fmt.Errorf("failed to load ratchet file: %w", err)
+	}
+
+	if mode == "check" {
+		return checkRatchet(ratchet, cov)
+	}
+
+	changed := ratchet.Update(cov)
+	for _, pkg := range changed {
+		log.Printf("Raised ratchet of package %s to %.2f%%", pkg, ratchet[pkg])
+	}
+
+	return writeRatchet(ratchet, *file)
+}
+
+// loadRatchet parses the ratchet file. If allowMissing is true, an empty
+// ratchet is returned if the file does not exist yet.
+func loadRatchet(path string, allowMissing bool) (report.Ratchet, error) {
+	ratchet, err := report.ParseRatchetFile(path)
+	if allowMissing && errors.Is(err, os.ErrNotExist) {
+		return report.Ratchet{}, nil
+	}
+
+	return ratchet, err
+}
+
+func checkRatchet(ratchet report.Ratchet, cov *coverage.Coverage) error {
+	violations := ratchet.Check(cov)
+	for _, v := range violations {
+		log.Println("FAIL:", v)
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %d package(s) below their ratchet or missing", errCheckFailed, len(violations))
+	}
+
+	log.Println("All packages meet their ratchet")
+	return nil
+}
+
+func writeRatchet(ratchet report.Ratchet, path string) error {
+	w, closeOutput, err := openOutput(path)
+	if err != nil {
+		return err
+	}
+
+	err = ratchet.Write(w)
+	if err != nil {
+		_ = closeOutput()
+		return fmt.Errorf("failed to write ratchet file: %w", err)
+	}
+
+	return closeOutput()
+}
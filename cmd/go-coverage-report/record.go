@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/fgrosse/go-coverage-report/report"
+)
+
+const recordUsage = `
+Usage: go-coverage-report record [OPTIONS] <COVERAGE_FILE>
+
+Parse the COVERAGE_FILE and append its total coverage and the coverage of each
+package to a history file. The history file contains one JSON document per line
+and can be passed to the -history flag of the diff command to show the coverage
+trend of each package in the report.
+
+The commit defaults to the GITHUB_SHA environment variable which is set in
+GitHub Actions.
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to the coverage file in the format produced by go test -coverprofile
+`
+
+func runRecord(args []string) error {
+	fs := newFlagSet("record", recordUsage)
+	history := fs.String("history", "coverage-history.jsonl", "the history file to append to")
+	commit := fs.String("commit", os.Getenv("GITHUB_SHA"), "the commit SHA of the coverage profile")
+	trim := fs.String("trim", "", "trim a prefix from all package names")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression")
+
+	args = parseFlags(fs, args, 1, 1)
+
+	if *commit == "" {
+		return fmt.Errorf("missing commit: use the -commit flag or set GITHUB_SHA")
+	}
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	cov, err := coverage.ParseFile(args[0], excludeRegex)
+	if err != nil {
+		return fmt.Errorf("failed to parse coverage: %w", err)
+	}
+
+	if *trim != "" {
+		cov.TrimPrefix(*trim)
+	}
+
+	entry := report.NewHistoryEntry(*commit, time.Now().UTC(), cov)
+	if err := report.AppendHistory(*history, entry); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/fgrosse/go-coverage-report/report"
+)
+
+const setopUsage = `
+Usage: go-coverage-report setop [OPTIONS] <COVERAGE_FILE> <COVERAGE_FILE>...
+
+Combine coverage profiles on the level of individual blocks using one of the
+following set operations:
+
+  union      Statements that are covered by any of the profiles
+  intersect  Statements that are covered by all of the profiles
+  subtract   Statements that are covered by the first profile but by none of the others
+
+The operation is applied from left to right. The result always contains all
+statements of the given profiles, so e.g. the coverage percentage of the
+"subtract" operation is the share of all statements that are only covered by
+the first profile.
+
+Examples:
+  # What did the new tests cover that nothing else did?
+  go-coverage-report setop -op=subtract -format=markdown new-tests.txt other-tests.txt
+
+  # What code is covered by both unit and integration tests?
+  go-coverage-report setop -op=intersect unit.txt integration.txt > both.txt
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to a coverage file in the format produced by go test -coverprofile
+`
+
+func runSetop(args []string) error {
+	fs := newFlagSet("setop", setopUsage)
+	op := fs.String("op", "", "the set operation ('union', 'intersect' or 'subtract')")
+	format := fs.String("format", "go", "output format ('go' for a coverage profile, 'markdown' or 'json' for a summary by package)")
+	output := fs.String("o", "", "write the result to this file instead of stdout")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression from all profiles")
+	trim := fs.String("trim", "", "trim a prefix from all file names")
+
+	args = parseFlags(fs, args, 2, -1)
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	var (
+		apply func(a, b *coverage.Coverage) (*coverage.Coverage, error)
+		title string
+	)
+
+	switch *op {
+	case "union":
+		apply, title = (*coverage.Coverage).Union, "Statements covered by any profile"
+	case "intersect":
+		apply, title = (*coverage.Coverage).Intersect, "Statements covered by all profiles"
+	case "subtract":
+		apply, title = (*coverage.Coverage).Subtract, fmt.Sprintf("Statements only covered by %s", args[0])
+	default:
+		return fmt.Errorf("unsupported set operation: %q", *op)
+	}
+
+	result, err := coverage.ParseFile(args[0], excludeRegex)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", args[0], err)
+	}
+
+	for _, path := range args[1:] {
+		cov, err := coverage.ParseFile(path, excludeRegex)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		result, err = apply(result, cov)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *trim != "" {
+		result.TrimPrefix(*trim)
+	}
+
+	w, closeOutput, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	summary := report.NewSummary(result)
+	summary.Title = title
+
+	switch strings.ToLower(*format) {
+	case "go":
+		err = result.Write(w)
+	case "markdown":
+		_, err = fmt.Fprintln(w, summary.Markdown())
+	case "json":
+		_, err = fmt.Fprintln(w, summary.JSON())
+	default:
+		err = fmt.Errorf("unsupported format: %q", *format)
+	}
+
+	if err != nil {
+		_ = closeOutput()
+		return err
+	}
+
+	return closeOutput()
+}
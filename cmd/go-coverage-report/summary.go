@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/fgrosse/go-coverage-report/report"
+)
+
+const summaryUsage = `
+Usage: go-coverage-report summary [OPTIONS] <COVERAGE_FILE>
+
+Parse the COVERAGE_FILE and print its total coverage and the coverage of each
+package as Markdown table or JSON document.
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to the coverage file in the format produced by go test -coverprofile
+`
+
+func runSummary(args []string) error {
+	fs := newFlagSet("summary", summaryUsage)
+	trim := fs.String("trim", "", "trim a prefix in the \"Package\" column of the summary")
+	format := fs.String("format", "markdown", "output format ('markdown' or 'json')")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression from the summary")
+
+	args = parseFlags(fs, args, 1, 1)
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	cov, err := coverage.ParseFile(args[0], excludeRegex)
+	if err != nil {
+		return fmt.Errorf("failed to parse coverage: %w", err)
+	}
+
+	if *trim != "" {
+		cov.TrimPrefix(*trim)
+	}
+
+	summary := report.NewSummary(cov)
+	switch strings.ToLower(*format) {
+	case "markdown":
+		fmt.Fprintln(os.Stdout, summary.Markdown())
+	case "json":
+		fmt.Fprintln(os.Stdout, summary.JSON())
+	default:
+		return fmt.Errorf("unsupported format: %q", *format)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/fgrosse/go-coverage-report/report"
+)
+
+const treemapUsage = `
+Usage: go-coverage-report treemap [OPTIONS] <COVERAGE_FILE>
+
+Render the packages of COVERAGE_FILE as SVG treemap. Each package is a rectangle
+whose area is proportional to its number of statements, nested into the rectangles
+of its parent directories. This makes it easy to spot large areas of uncovered code.
+
+Packages are colored by their coverage from red (0%) to green (100%). If the -old
+flag is given, they are colored by their coverage change compared to the old profile
+instead (red for a decrease, gray for no change and green for an increase).
+With -accessible, a color-blind-safe palette of blue and orange is used instead
+and coverage changes are additionally marked with ▲ and ▼ icons.
+
+ARGUMENTS:
+  COVERAGE_FILE  The path to the coverage file in the format produced by go test -coverprofile
+`
+
+func runTreemap(args []string) error {
+	fs := newFlagSet("treemap", treemapUsage)
+	old := fs.String("old", "", "color the packages by their coverage change compared to this coverage file")
+	width := fs.Int("width", 1200, "width of the treemap in pixels")
+	height := fs.Int("height", 800, "height of the treemap in pixels")
+	output := fs.String("o", "", "write the treemap to this file instead of stdout")
+	exclude := fs.String("exclude", "", "exclude files matching the given regular expression")
+	trim := fs.String("trim", "", "trim a prefix from all package names")
+	accessible := fs.Bool("accessible", false, "use color-blind-safe colors and mark coverage changes with arrows")
+
+	args = parseFlags(fs, args, 1, 1)
+
+	excludeRegex, err := parseExclude(*exclude)
+	if err != nil {
+		return err
+	}
+
+	cov, err := coverage.ParseFile(args[0], excludeRegex)
+	if err != nil {
+		return fmt.Errorf("failed to parse coverage: %w", err)
+	}
+
+	opts := report.TreemapOptions{Width: *width, Height: *height, Accessible: *accessible}
+	if *old != "" {
+		opts.Old, err = coverage.ParseFile(*old, excludeRegex)
+		if err != nil {
+			return fmt.Errorf("failed to parse old coverage: %w", err)
+		}
+	}
+
+	if *trim != "" {
+		cov.TrimPrefix(*trim)
+		if opts.Old != nil {
+			opts.Old.TrimPrefix(*trim)
+		}
+	}
+
+	w, closeOutput, err := openOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(w, report.Treemap(cov, opts))
+	if err != nil {
+		_ = closeOutput()
+		return fmt.Errorf("failed to write treemap: %w", err)
+	}
+
+	return closeOutput()
+}
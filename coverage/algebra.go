@@ -0,0 +1,161 @@
+package coverage
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// blockPos identifies a block by its position in a file.
+type blockPos struct {
+	StartLine, StartCol int
+	EndLine, EndCol     int
+}
+
+// Union returns the coverage of the statements that are covered by c or by
+// other. Counts of blocks at the same location are added, or ORed in "set" mode.
+func (c *Coverage) Union(other *Coverage) (*Coverage, error) {
+	return c.combine(other, unionCounts)
+}
+
+func unionCounts(mode string, a, b int) int {
+	if mode == "set" {
+		return a | b
+	}
+	return a + b
+}
+
+// Intersect returns the coverage of the statements that are covered by both
+// c and other. The count of each block is the minimum of both counts.
+func (c *Coverage) Intersect(other *Coverage) (*Coverage, error) {
+	return c.combine(other, func(_ string, a, b int) int {
+		return min(a, b)
+	})
+}
+
+// Subtract returns the coverage of the statements that are covered by c but
+// not by other. The count of each block that is also covered by other is zero.
+func (c *Coverage) Subtract(other *Coverage) (*Coverage, error) {
+	return c.combine(other, func(_ string, a, b int) int {
+		if b > 0 {
+			return 0
+		}
+		return a
+	})
+}
+
+// combine applies op to the counts of all blocks of c and other. The result
+// contains all blocks of both inputs so the total number of statements is
+// always the one of the union of c and other. Blocks that are missing in
+// one of the inputs are treated as not covered in that input. If the inputs
+// use different modes, all files of the result use the "set" mode so that
+// it can be written as a single profile.
+func (c *Coverage) combine(other *Coverage, op func(mode string, a, b int) int) (*Coverage, error) {
+	names := map[string]bool{}
+	var modes []string
+	for _, cov := range []*Coverage{c, other} {
+		for name, p := range cov.Files {
+			names[name] = true
+			if !slices.Contains(modes, p.Mode) {
+				modes = append(modes, p.Mode)
+			}
+		}
+	}
+
+	mode := "set"
+	if len(modes) == 1 {
+		mode = modes[0]
+	}
+
+	profiles := make([]*Profile, 0, len(names))
+	for name := range names {
+		p, err := combineProfiles(name, mode, c.Files[name], other.Files[name], op)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	sort.Sort(byFileName(profiles))
+	return NewUnion(profiles)
+}
+
+// combineProfiles combines the profiles of a single file in the given mode.
+// If it is "set", the counts are reduced to whether a block was covered at
+// all since counts of different modes cannot be compared.
+func combineProfiles(name, mode string, a, b *Profile, op func(mode string, a, b int) int) (*Profile, error) {
+	blocks := map[blockPos]ProfileBlock{}
+	countsA := map[blockPos]int{}
+	countsB := map[blockPos]int{}
+
+	collect := func(p *Profile, counts map[blockPos]int) error {
+		if p == nil {
+			return nil
+		}
+
+		for _, b := range p.Blocks {
+			pos := blockPos{b.StartLine, b.StartCol, b.EndLine, b.EndCol}
+			if existing, ok := blocks[pos]; ok && existing.NumStmt != b.NumStmt {
+				return errors.Errorf("inconsistent NumStmt of %q at %d.%d: changed from %d to %d",
+					name, b.StartLine, b.StartCol, existing.NumStmt, b.NumStmt)
+			}
+
+			blocks[pos] = b
+			counts[pos] += b.Count
+		}
+
+		return nil
+	}
+
+	if err := collect(a, countsA); err != nil {
+		return nil, err
+	}
+	if err := collect(b, countsB); err != nil {
+		return nil, err
+	}
+
+	if mode == "set" {
+		for _, counts := range []map[blockPos]int{countsA, countsB} {
+			for pos, n := range counts {
+				counts[pos] = min(n, 1)
+			}
+		}
+	}
+
+	result := &Profile{
+		FileName:  name,
+		Mode:      mode,
+		Blocks:    make([]ProfileBlock, 0, len(blocks)),
+		Platforms: mergePlatforms(a, b),
+	}
+	for pos, block := range blocks {
+		block.Count = op(mode, countsA[pos], countsB[pos])
+		result.Blocks = append(result.Blocks, block)
+	}
+
+	if err := normalize(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// mergePlatforms returns the sorted union of the platforms of a and b.
+func mergePlatforms(a, b *Profile) []string {
+	var platforms []string
+	for _, p := range []*Profile{a, b} {
+		if p == nil {
+			continue
+		}
+
+		for _, platform := range p.Platforms {
+			if !slices.Contains(platforms, platform) {
+				platforms = append(platforms, platform)
+			}
+		}
+	}
+
+	slices.Sort(platforms)
+	return platforms
+}
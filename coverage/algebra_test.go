@@ -0,0 +1,90 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverage_SetOperations(t *testing.T) {
+	unit, err := Parse(strings.NewReader(`mode: count
+example.com/foo/a.go:1.1,2.2 2 1
+example.com/foo/a.go:3.1,4.2 1 2
+example.com/foo/a.go:5.1,6.2 3 0
+`), nil)
+	require.NoError(t, err)
+
+	integration, err := Parse(strings.NewReader(`mode: count
+example.com/foo/a.go:1.1,2.2 2 0
+example.com/foo/a.go:3.1,4.2 1 5
+example.com/foo/a.go:5.1,6.2 3 0
+example.com/foo/b.go:1.1,2.2 4 1
+`), nil)
+	require.NoError(t, err)
+
+	counts := func(cov *Coverage, file string) []int {
+		var result []int
+		for _, b := range cov.Files[file].Blocks {
+			result = append(result, b.Count)
+		}
+		return result
+	}
+
+	union, err := unit.Union(integration)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 7, 0}, counts(union, "example.com/foo/a.go"))
+	assert.Equal(t, []int{1}, counts(union, "example.com/foo/b.go"))
+	assert.EqualValues(t, 10, union.TotalStmt)
+	assert.EqualValues(t, 7, union.CoveredStmt)
+
+	intersection, err := unit.Intersect(integration)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2, 0}, counts(intersection, "example.com/foo/a.go"))
+	assert.Equal(t, []int{0}, counts(intersection, "example.com/foo/b.go"))
+	assert.EqualValues(t, 10, intersection.TotalStmt)
+	assert.EqualValues(t, 1, intersection.CoveredStmt)
+
+	difference, err := unit.Subtract(integration)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 0, 0}, counts(difference, "example.com/foo/a.go"))
+	assert.Equal(t, []int{0}, counts(difference, "example.com/foo/b.go"))
+	assert.EqualValues(t, 10, difference.TotalStmt)
+	assert.EqualValues(t, 2, difference.CoveredStmt)
+
+	// The inputs must not be modified.
+	assert.Equal(t, []int{1, 2, 0}, counts(unit, "example.com/foo/a.go"))
+}
+
+func TestCoverage_SetOperations_DifferentModes(t *testing.T) {
+	a, err := Parse(strings.NewReader("mode: set\na.go:1.1,2.2 2 1\na.go:3.1,4.2 1 0\n"), nil)
+	require.NoError(t, err)
+
+	b, err := Parse(strings.NewReader("mode: count\na.go:1.1,2.2 2 0\na.go:3.1,4.2 1 7\n"), nil)
+	require.NoError(t, err)
+
+	union, err := a.Union(b)
+	require.NoError(t, err)
+
+	p := union.Files["a.go"]
+	assert.Equal(t, "set", p.Mode)
+	assert.Equal(t, 1, p.Blocks[0].Count)
+	assert.Equal(t, 1, p.Blocks[1].Count)
+	assert.EqualValues(t, 3, p.CoveredStmt)
+}
+
+func TestCoverage_SetOperations_DifferentModes_Write(t *testing.T) {
+	a, err := Parse(strings.NewReader("mode: set\na.go:1.1,2.2 2 1\n"), nil)
+	require.NoError(t, err)
+
+	b, err := Parse(strings.NewReader("mode: count\na.go:1.1,2.2 2 3\nb.go:1.1,2.2 1 5\n"), nil)
+	require.NoError(t, err)
+
+	union, err := a.Union(b)
+	require.NoError(t, err)
+
+	buf := new(strings.Builder)
+	require.NoError(t, union.Write(buf))
+	assert.Equal(t, "mode: set\na.go:1.1,2.2 2 1\nb.go:1.1,2.2 1 1\n", buf.String())
+}
@@ -0,0 +1,185 @@
+// Package coverage parses Go coverage profiles as produced by
+// "go test -coverprofile" and aggregates them by file and package.
+package coverage
+
+import (
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Coverage contains the parsed profiles of all files of a coverage profile
+// together with the aggregated statement counts.
+type Coverage struct {
+	Files       map[string]*Profile
+	TotalStmt   int64
+	CoveredStmt int64
+	MissedStmt  int64
+}
+
+// ParseFile parses the coverage profile in the given file. Files whose name
+// matches the optional exclude regular expression are skipped.
+func ParseFile(filename string, exclude *regexp.Regexp) (*Coverage, error) {
+	pp, err := ParseProfiles(filename, exclude)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse profiles")
+	}
+
+	return New(pp), nil
+}
+
+// Parse parses a coverage profile from the given reader. Files whose name
+// matches the optional exclude regular expression are skipped.
+func Parse(rd io.Reader, exclude *regexp.Regexp) (*Coverage, error) {
+	pp, err := ParseProfilesFromReader(rd, exclude)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse profiles")
+	}
+
+	return New(pp), nil
+}
+
+// New aggregates the given profiles into a Coverage. If a file is contained
+// more than once in profiles (e.g. because profiles of multiple platforms
+// were passed), the union of its profiles is used (see Coverage.Union). If
+// the profiles use different modes, all files use the "set" mode so that the
+// result can be written as a single profile.
+// New panics if the profiles of a file are inconsistent. Use NewUnion to
+// handle this case gracefully.
+func New(profiles []*Profile) *Coverage {
+	cov, err := NewUnion(profiles)
+	if err != nil {
+		panic(err)
+	}
+
+	return cov
+}
+
+// NewUnion is like New but returns an error instead of panicking if the
+// profiles of a file that is contained more than once are inconsistent.
+func NewUnion(profiles []*Profile) (*Coverage, error) {
+	var modes []string
+	for _, p := range profiles {
+		if p != nil && !slices.Contains(modes, p.Mode) {
+			modes = append(modes, p.Mode)
+		}
+	}
+
+	mode := "set"
+	if len(modes) == 1 {
+		mode = modes[0]
+	}
+
+	cov := &Coverage{Files: map[string]*Profile{}}
+	for _, p := range profiles {
+		if err := cov.add(p, mode); err != nil {
+			return nil, err
+		}
+	}
+
+	return cov, nil
+}
+
+func (c *Coverage) add(p *Profile, mode string) error {
+	if p == nil {
+		return nil
+	}
+
+	// Profiles of another mode are converted even if the file is only
+	// contained once so that all files of the result use the same mode.
+	existing, ok := c.Files[p.FileName]
+	if ok || p.Mode != mode {
+		merged, err := combineProfiles(p.FileName, mode, existing, p, unionCounts)
+		if err != nil {
+			return err
+		}
+		p = merged
+	}
+	if ok {
+		c.TotalStmt -= existing.TotalStmt
+		c.CoveredStmt -= existing.CoveredStmt
+		c.MissedStmt -= existing.MissedStmt
+	}
+
+	c.Files[p.FileName] = p
+	c.TotalStmt += p.TotalStmt
+	c.CoveredStmt += p.CoveredStmt
+	c.MissedStmt += p.MissedStmt
+	return nil
+}
+
+// Percent returns the percentage of covered statements.
+func (c *Coverage) Percent() float64 {
+	if c.TotalStmt == 0 {
+		return 0
+	}
+
+	return float64(c.CoveredStmt) / float64(c.TotalStmt) * 100
+}
+
+// ByPackage groups the files by their directory and returns the Coverage
+// of each package.
+func (c *Coverage) ByPackage() map[string]*Coverage {
+	packages := map[string][]string{} // maps package paths to files
+	for file := range c.Files {
+		pkg := path.Dir(file)
+		packages[pkg] = append(packages[pkg], file)
+	}
+
+	pkgCovs := make(map[string]*Coverage, len(packages))
+	for pkg, files := range packages {
+		var profiles []*Profile
+		for _, file := range files {
+			profiles = append(profiles, c.Files[file])
+		}
+
+		pkgCovs[pkg] = New(profiles)
+	}
+
+	return pkgCovs
+}
+
+// TrimPrefix removes the given prefix from all file names.
+func (c *Coverage) TrimPrefix(prefix string) {
+	files := make(map[string]*Profile, len(c.Files))
+	for name, cov := range c.Files {
+		cov.FileName = TrimPathPrefix(name, prefix)
+		files[cov.FileName] = cov
+	}
+
+	c.Files = files
+}
+
+// Profiles returns the profiles of all files sorted by file name.
+func (c *Coverage) Profiles() []*Profile {
+	profiles := make([]*Profile, 0, len(c.Files))
+	for _, p := range c.Files {
+		profiles = append(profiles, p)
+	}
+
+	sort.Sort(byFileName(profiles))
+	return profiles
+}
+
+// Write writes the normalized profiles of all files in the text format of
+// "go test -coverprofile" (see WriteProfiles).
+func (c *Coverage) Write(w io.Writer) error {
+	return WriteProfiles(w, c.Profiles())
+}
+
+// TrimPathPrefix removes the given prefix and any leading slash from name.
+// If nothing remains, "." is returned.
+func TrimPathPrefix(name, prefix string) string {
+	trimmed := strings.TrimPrefix(name, prefix)
+	trimmed = strings.TrimPrefix(trimmed, "/")
+	if trimmed == "" {
+		trimmed = "."
+	}
+
+	return trimmed
+}
@@ -0,0 +1,192 @@
+package coverage
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile(t *testing.T) {
+	cov, err := ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 102, cov.TotalStmt)
+	assert.EqualValues(t, 92, cov.CoveredStmt)
+	assert.EqualValues(t, 10, cov.MissedStmt)
+	assert.InDelta(t, 90.196, cov.Percent(), 0.001)
+}
+
+func TestCoverage_ByPackage(t *testing.T) {
+	cov, err := ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	pkgs := cov.ByPackage()
+	assert.Len(t, pkgs, 1)
+
+	pkgCov := pkgs["github.com/fgrosse/prioqueue"]
+	assert.NotNil(t, pkgCov)
+	assert.EqualValues(t, 102, pkgCov.TotalStmt)
+	assert.EqualValues(t, 92, pkgCov.CoveredStmt)
+	assert.EqualValues(t, 10, pkgCov.MissedStmt)
+}
+
+func TestCoverage_ByPackageFiltered(t *testing.T) {
+	regex := regexp.MustCompile(".*max_.*.go")
+	cov, err := ParseFile("testdata/01-new-coverage.txt", regex)
+	require.NoError(t, err)
+
+	pkgs := cov.ByPackage()
+	assert.Len(t, pkgs, 1)
+
+	pkgCov := pkgs["github.com/fgrosse/prioqueue"]
+	assert.NotNil(t, pkgCov)
+	assert.EqualValues(t, 52, pkgCov.TotalStmt)
+	assert.EqualValues(t, 42, pkgCov.CoveredStmt)
+	assert.EqualValues(t, 10, pkgCov.MissedStmt)
+}
+
+func TestCoverage_ByPackage_DuplicatedBlocks_TotalBlockValueReported(t *testing.T) {
+	cov, err := ParseFile("testdata/03-coverage-with-duplicate-blocks.txt", nil)
+	require.NoError(t, err)
+
+	pkgs := cov.ByPackage()
+
+	pkgCov := pkgs["github.com/fgrosse/database"]
+	assert.NotNil(t, pkgCov)
+	assert.EqualValues(t, 617, pkgCov.TotalStmt)
+	assert.EqualValues(t, 437, pkgCov.CoveredStmt)
+	assert.EqualValues(t, 180, pkgCov.MissedStmt)
+	assert.InDelta(t, 70.83, pkgCov.Percent(), 0.01)
+}
+
+func TestCoverage_ByFile_DuplicatedBlocks_TotalBlockValueReported(t *testing.T) {
+	cov, err := ParseFile("testdata/03-coverage-with-duplicate-blocks.txt", nil)
+	require.NoError(t, err)
+
+	profile := cov.Files["github.com/fgrosse/database/cRepo.go"]
+
+	assert.NotNil(t, profile)
+	assert.EqualValues(t, 42, profile.TotalStmt)
+	assert.EqualValues(t, 38, profile.CoveredStmt)
+	assert.EqualValues(t, 4, profile.MissedStmt)
+	assert.InDelta(t, 90.47, profile.CoveragePercent(), 0.01)
+}
+
+func TestParse(t *testing.T) {
+	profile := `mode: set
+github.com/fgrosse/example/foo.go:3.10,5.2 2 1
+github.com/fgrosse/example/foo.go:7.10,9.2 1 0
+github.com/fgrosse/example/bar/bar.go:3.10,5.2 4 1
+`
+
+	cov, err := Parse(strings.NewReader(profile), nil)
+	require.NoError(t, err)
+
+	assert.Len(t, cov.Files, 2)
+	assert.EqualValues(t, 7, cov.TotalStmt)
+	assert.EqualValues(t, 6, cov.CoveredStmt)
+	assert.EqualValues(t, 1, cov.MissedStmt)
+}
+
+func TestCoverage_Write(t *testing.T) {
+	profile := `mode: set
+github.com/fgrosse/example/foo/foo.go:7.10,9.2 1 0
+github.com/fgrosse/example/foo/foo.go:3.10,5.2 2 1
+github.com/fgrosse/example/bar/bar.go:3.10,5.2 4 1
+github.com/fgrosse/example/foo/foo.go:3.10,5.2 2 0
+github.com/fgrosse/example/vendor/baz.go:1.1,2.2 1 1
+`
+
+	cov, err := Parse(strings.NewReader(profile), regexp.MustCompile("/vendor/"))
+	require.NoError(t, err)
+
+	cov.TrimPrefix("github.com/fgrosse/example")
+
+	buf := new(strings.Builder)
+	err = cov.Write(buf)
+	require.NoError(t, err)
+
+	expected := `mode: set
+bar/bar.go:3.10,5.2 4 1
+foo/foo.go:3.10,5.2 2 1
+foo/foo.go:7.10,9.2 1 0
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestCoverage_TrimPrefix_RepeatedPrefix(t *testing.T) {
+	cov := New([]*Profile{
+		{FileName: "a/a/a.go"},
+		{FileName: "a/b.go"},
+	})
+
+	cov.TrimPrefix("a/")
+
+	assert.Contains(t, cov.Files, "a/a.go")
+	assert.Contains(t, cov.Files, "b.go")
+	assert.Len(t, cov.Files, 2)
+}
+
+func TestNew_UnionOfPlatforms(t *testing.T) {
+	linux, err := ParseProfilesFromReader(strings.NewReader(`mode: set
+example.com/foo/foo.go:1.1,2.2 2 1
+example.com/foo/foo.go:3.1,4.2 1 0
+example.com/foo/foo_linux.go:1.1,2.2 3 1
+`), nil)
+	require.NoError(t, err)
+
+	windows, err := ParseProfilesFromReader(strings.NewReader(`mode: set
+example.com/foo/foo.go:1.1,2.2 2 0
+example.com/foo/foo.go:3.1,4.2 1 1
+example.com/foo/foo_windows.go:1.1,2.2 5 0
+`), nil)
+	require.NoError(t, err)
+
+	for _, p := range linux {
+		p.Platforms = []string{"linux"}
+	}
+	for _, p := range windows {
+		p.Platforms = []string{"windows"}
+	}
+
+	cov := New(append(linux, windows...))
+
+	assert.Len(t, cov.Files, 3)
+	assert.EqualValues(t, 11, cov.TotalStmt)
+	assert.EqualValues(t, 6, cov.CoveredStmt)
+	assert.EqualValues(t, 5, cov.MissedStmt)
+
+	foo := cov.Files["example.com/foo/foo.go"]
+	assert.EqualValues(t, 3, foo.CoveredStmt)
+	assert.Equal(t, []string{"linux", "windows"}, foo.Platforms)
+	assert.Equal(t, []string{"linux"}, cov.Files["example.com/foo/foo_linux.go"].Platforms)
+	assert.Equal(t, []string{"windows"}, cov.Files["example.com/foo/foo_windows.go"].Platforms)
+}
+
+func TestNew_UnionOfPlatformsWithDifferentModes(t *testing.T) {
+	linux, err := ParseProfilesFromReader(strings.NewReader(`mode: set
+a/foo.go:1.1,2.2 2 1
+a/linux.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	windows, err := ParseProfilesFromReader(strings.NewReader(`mode: count
+a/foo.go:1.1,2.2 2 3
+a/win.go:1.1,2.2 1 5
+`), nil)
+	require.NoError(t, err)
+
+	cov, err := NewUnion(append(linux, windows...))
+	require.NoError(t, err)
+
+	for name, p := range cov.Files {
+		assert.Equal(t, "set", p.Mode, name)
+	}
+
+	buf := new(strings.Builder)
+	require.NoError(t, cov.Write(buf))
+	assert.Equal(t, "mode: set\na/foo.go:1.1,2.2 2 1\na/linux.go:1.1,2.2 1 1\na/win.go:1.1,2.2 1 1\n", buf.String())
+}
@@ -0,0 +1,79 @@
+package coverage
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+type jsonProfiles struct {
+	Mode  string        `json:"mode"`
+	Files []jsonProfile `json:"files"`
+}
+
+type jsonProfile struct {
+	FileName string      `json:"file"`
+	Blocks   []jsonBlock `json:"blocks"`
+}
+
+type jsonBlock struct {
+	StartLine int `json:"start_line"`
+	StartCol  int `json:"start_col"`
+	EndLine   int `json:"end_line"`
+	EndCol    int `json:"end_col"`
+	NumStmt   int `json:"num_stmt"`
+	Count     int `json:"count"`
+}
+
+// WriteProfilesJSON writes the profiles including all their blocks as JSON
+// document. The document can be read again via ParseProfilesJSON.
+func WriteProfilesJSON(w io.Writer, profiles []*Profile) error {
+	doc := jsonProfiles{Mode: "set", Files: make([]jsonProfile, 0, len(profiles))}
+	if len(profiles) > 0 {
+		doc.Mode = profiles[0].Mode
+	}
+
+	sorted := make([]*Profile, len(profiles))
+	copy(sorted, profiles)
+	sort.Sort(byFileName(sorted))
+
+	for _, p := range sorted {
+		if p.Mode != doc.Mode {
+			return errors.Errorf("cannot write profile of %q with mode %q into profile with mode %q", p.FileName, p.Mode, doc.Mode)
+		}
+
+		f := jsonProfile{FileName: p.FileName, Blocks: make([]jsonBlock, 0, len(p.Blocks))}
+		for _, b := range p.Blocks {
+			f.Blocks = append(f.Blocks, jsonBlock(b))
+		}
+
+		doc.Files = append(doc.Files, f)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(doc)
+}
+
+// ParseProfilesJSON parses profiles that were written via WriteProfilesJSON.
+func ParseProfilesJSON(rd io.Reader) ([]*Profile, error) {
+	var doc jsonProfiles
+	err := json.NewDecoder(rd).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*Profile, 0, len(doc.Files))
+	for _, f := range doc.Files {
+		p := &Profile{FileName: f.FileName, Mode: doc.Mode}
+		for _, b := range f.Blocks {
+			p.Blocks = append(p.Blocks, ProfileBlock(b))
+		}
+
+		profiles = append(profiles, p)
+	}
+
+	return Merge(profiles)
+}
@@ -0,0 +1,42 @@
+package coverage
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Merge combines the given lists of profiles (e.g. from multiple test runs)
+// into a single list of profiles sorted by file name. Blocks at the same
+// location are merged by adding their counts or, in "set" mode, by using
+// the logical OR of their counts. All profiles must use the same mode.
+func Merge(profileLists ...[]*Profile) ([]*Profile, error) {
+	files := map[string]*Profile{}
+	for _, profiles := range profileLists {
+		for _, p := range profiles {
+			merged, ok := files[p.FileName]
+			if !ok {
+				merged = &Profile{FileName: p.FileName, Mode: p.Mode}
+				files[p.FileName] = merged
+			}
+
+			if merged.Mode != p.Mode {
+				return nil, errors.Errorf("cannot merge profiles of %q with different modes %q and %q", p.FileName, merged.Mode, p.Mode)
+			}
+
+			merged.Blocks = append(merged.Blocks, p.Blocks...)
+			merged.Platforms = mergePlatforms(merged, p)
+		}
+	}
+
+	result := make([]*Profile, 0, len(files))
+	for _, p := range files {
+		if err := normalize(p); err != nil {
+			return nil, errors.Wrapf(err, "failed to merge profiles of %q", p.FileName)
+		}
+		result = append(result, p)
+	}
+
+	sort.Sort(byFileName(result))
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	a, err := ParseProfilesFromReader(strings.NewReader(`mode: count
+example.com/foo/a.go:1.1,2.2 2 1
+example.com/foo/a.go:3.1,4.2 1 0
+`), nil)
+	require.NoError(t, err)
+
+	b, err := ParseProfilesFromReader(strings.NewReader(`mode: count
+example.com/foo/a.go:3.1,4.2 1 3
+example.com/foo/b.go:1.1,2.2 4 0
+`), nil)
+	require.NoError(t, err)
+
+	merged, err := Merge(a, b)
+	require.NoError(t, err)
+	require.Len(t, merged, 2)
+
+	assert.Equal(t, "example.com/foo/a.go", merged[0].FileName)
+	assert.Equal(t, []ProfileBlock{
+		{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 2, Count: 1},
+		{StartLine: 3, StartCol: 1, EndLine: 4, EndCol: 2, NumStmt: 1, Count: 3},
+	}, merged[0].Blocks)
+	assert.EqualValues(t, 3, merged[0].TotalStmt)
+	assert.EqualValues(t, 3, merged[0].CoveredStmt)
+	assert.EqualValues(t, 0, merged[0].MissedStmt)
+
+	assert.Equal(t, "example.com/foo/b.go", merged[1].FileName)
+	assert.EqualValues(t, 4, merged[1].MissedStmt)
+
+	// The input profiles must not be modified.
+	assert.Equal(t, 0, a[0].Blocks[1].Count)
+}
+
+func TestMerge_DifferentModes(t *testing.T) {
+	a := []*Profile{{FileName: "a.go", Mode: "set"}}
+	b := []*Profile{{FileName: "a.go", Mode: "count"}}
+
+	_, err := Merge(a, b)
+	assert.Error(t, err)
+}
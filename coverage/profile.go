@@ -6,7 +6,7 @@
 // generated by "go test -coverprofile=cover.out".
 // It is mostly a copy of golang.org/x/tools/cover/profile.go with some modifications.
 
-package main
+package coverage
 
 import (
 	"bufio"
@@ -27,6 +27,10 @@ type Profile struct {
 	Mode     string
 	Blocks   []ProfileBlock `json:"-"`
 
+	// Platforms contains the names of the platforms (e.g. of a CI build
+	// matrix) whose profiles contributed to this profile, if known.
+	Platforms []string `json:",omitempty"`
+
 	TotalStmt   int64
 	CoveredStmt int64
 	MissedStmt  int64
@@ -97,39 +101,9 @@ func ParseProfilesFromReader(rd io.Reader, exclude *regexp.Regexp) ([]*Profile,
 		return nil, err
 	}
 	for _, p := range files {
-		sort.Sort(blocksByStart(p.Blocks))
-		// Merge samples from the same location.
-		j := 1
-		for i := 1; i < len(p.Blocks); i++ {
-			b := p.Blocks[i]
-			last := p.Blocks[j-1]
-			if b.StartLine == last.StartLine &&
-				b.StartCol == last.StartCol &&
-				b.EndLine == last.EndLine &&
-				b.EndCol == last.EndCol {
-				if b.NumStmt != last.NumStmt {
-					return nil, fmt.Errorf("inconsistent NumStmt: changed from %d to %d", last.NumStmt, b.NumStmt)
-				}
-				if mode == "set" {
-					p.Blocks[j-1].Count |= b.Count
-				} else {
-					p.Blocks[j-1].Count += b.Count
-				}
-				continue
-			}
-			p.Blocks[j] = b
-			j++
-		}
-		p.Blocks = p.Blocks[:j]
-
-		for _, b := range p.Blocks {
-			p.TotalStmt += int64(b.NumStmt)
-			if b.Count > 0 {
-				// If we got at least a single hit in this block we say it was covered
-				p.CoveredStmt += int64(b.NumStmt)
-			}
+		if err := normalize(p); err != nil {
+			return nil, err
 		}
-		p.MissedStmt = p.TotalStmt - p.CoveredStmt
 	}
 	// Generate a sorted slice.
 	profiles := make([]*Profile, 0, len(files))
@@ -140,6 +114,50 @@ func ParseProfilesFromReader(rd io.Reader, exclude *regexp.Regexp) ([]*Profile,
 	return profiles, nil
 }
 
+// normalize sorts the blocks of p, merges samples from the same location
+// and computes the statement counts of the profile.
+func normalize(p *Profile) error {
+	p.TotalStmt, p.CoveredStmt, p.MissedStmt = 0, 0, 0
+	if len(p.Blocks) == 0 {
+		return nil
+	}
+
+	sort.Sort(blocksByStart(p.Blocks))
+	// Merge samples from the same location.
+	j := 1
+	for i := 1; i < len(p.Blocks); i++ {
+		b := p.Blocks[i]
+		last := p.Blocks[j-1]
+		if b.StartLine == last.StartLine &&
+			b.StartCol == last.StartCol &&
+			b.EndLine == last.EndLine &&
+			b.EndCol == last.EndCol {
+			if b.NumStmt != last.NumStmt {
+				return fmt.Errorf("inconsistent NumStmt: changed from %d to %d", last.NumStmt, b.NumStmt)
+			}
+			if p.Mode == "set" {
+				p.Blocks[j-1].Count |= b.Count
+			} else {
+				p.Blocks[j-1].Count += b.Count
+			}
+			continue
+		}
+		p.Blocks[j] = b
+		j++
+	}
+	p.Blocks = p.Blocks[:j]
+
+	for _, b := range p.Blocks {
+		p.TotalStmt += int64(b.NumStmt)
+		if b.Count > 0 {
+			// If we got at least a single hit in this block we say it was covered
+			p.CoveredStmt += int64(b.NumStmt)
+		}
+	}
+	p.MissedStmt = p.TotalStmt - p.CoveredStmt
+	return nil
+}
+
 // parseLine parses a line from a coverage file.
 // It is equivalent to the regex
 // ^(.+):([0-9]+)\.([0-9]+),([0-9]+)\.([0-9]+) ([0-9]+) ([0-9]+)$
@@ -0,0 +1,48 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// WriteProfiles writes the profiles in the text format that is produced by
+// "go test -coverprofile". The output is sorted by file name and block
+// position so that it is deterministic. All profiles must use the same mode.
+// If profiles is empty, only the mode line of "set" mode is written.
+func WriteProfiles(w io.Writer, profiles []*Profile) error {
+	mode := "set"
+	if len(profiles) > 0 {
+		mode = profiles[0].Mode
+	}
+
+	sorted := make([]*Profile, len(profiles))
+	copy(sorted, profiles)
+	sort.Sort(byFileName(sorted))
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "mode: %s\n", mode)
+	for _, p := range sorted {
+		if p.Mode != mode {
+			return errors.Errorf("cannot write profile of %q with mode %q into profile with mode %q", p.FileName, p.Mode, mode)
+		}
+
+		blocks := make([]ProfileBlock, len(p.Blocks))
+		copy(blocks, p.Blocks)
+		sort.Stable(blocksByStart(blocks))
+
+		for _, b := range blocks {
+			fmt.Fprintf(bw, "%s:%d.%d,%d.%d %d %d\n",
+				p.FileName,
+				b.StartLine, b.StartCol,
+				b.EndLine, b.EndCol,
+				b.NumStmt, b.Count,
+			)
+		}
+	}
+
+	return bw.Flush()
+}
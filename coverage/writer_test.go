@@ -0,0 +1,42 @@
+package coverage
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteProfiles(t *testing.T) {
+	profiles, err := ParseProfiles("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	err = WriteProfiles(buf, profiles)
+	require.NoError(t, err)
+
+	// Writing the parsed profiles again must result in the same profiles.
+	parsed, err := ParseProfilesFromReader(bytes.NewReader(buf.Bytes()), nil)
+	require.NoError(t, err)
+	assert.Equal(t, profiles, parsed)
+
+	// The output is deterministic and sorted by file name and block position.
+	original, err := os.ReadFile("testdata/01-new-coverage.txt")
+	require.NoError(t, err)
+	assert.Equal(t, string(original), buf.String())
+}
+
+func TestWriteProfilesJSON(t *testing.T) {
+	profiles, err := ParseProfiles("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	err = WriteProfilesJSON(buf, profiles)
+	require.NoError(t, err)
+
+	parsed, err := ParseProfilesJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, profiles, parsed)
+}
@@ -0,0 +1,30 @@
+package report
+
+import (
+	"fmt"
+	"math"
+)
+
+// AccessiblePolicy scores coverage changes with words instead of emojis so
+// that they can be read by screen readers and survive plain text. It is the
+// default scoring policy of accessible reports.
+var AccessiblePolicy = ThresholdPolicy{Levels: []ScoreLevel{
+	{Min: math.Inf(-1), Symbol: "major regression"},
+	{Min: -10, Symbol: "regression"},
+	{Min: 0, Symbol: "improvement"},
+	{Min: 10, Symbol: "major improvement"},
+}}
+
+// accessibleDeltaText formats the coverage change with an arrow and in
+// percentage points instead of Markdown emphasis (e.g. "▼ 12.30 pp").
+func accessibleDeltaText(newPercent, oldPercent float64) string {
+	diff := newPercent - oldPercent
+	switch {
+	case diff > 0:
+		return fmt.Sprintf("▲ %.2f pp", diff)
+	case diff < 0:
+		return fmt.Sprintf("▼ %.2f pp", -diff)
+	default:
+		return "no change"
+	}
+}
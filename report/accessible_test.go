@@ -0,0 +1,68 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessibleDeltaText(t *testing.T) {
+	assert.Equal(t, "▼ 12.30 pp", accessibleDeltaText(50, 62.3))
+	assert.Equal(t, "▲ 1.50 pp", accessibleDeltaText(51.5, 50))
+	assert.Equal(t, "no change", accessibleDeltaText(50, 50))
+}
+
+func TestReport_Markdown_Accessible(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, []string{"github.com/fgrosse/prioqueue/min_heap.go", "github.com/fgrosse/other/other.go"})
+	report.TrimPrefix("github.com/fgrosse/")
+	report.Accessible = true
+
+	expected := "### Merging this branch will decrease overall coverage\n" +
+		"\n" +
+		"| Impacted Packages | Coverage Δ | Score |\n" +
+		"|-------------------|------------|---------|\n" +
+		"| other | 0.00% (no change) |  |\n" +
+		"| prioqueue | 90.20% (▼ 9.80 pp) | regression |\n"
+	assert.Contains(t, report.Markdown(), expected)
+	assert.Contains(t, report.Markdown(), "| prioqueue/min_heap.go | 80.77% (▼ 19.23 pp) | 52 (+2) | 42 (-8) | 10 (+10) | major regression |\n")
+	assert.NotContains(t, report.Markdown(), ":robot:")
+	assert.NotContains(t, report.Markdown(), ":skull:")
+	assert.NotContains(t, report.Markdown(), "**")
+
+	report.Scoring = DefaultScoringPolicy
+	assert.True(t, strings.HasPrefix(report.Markdown(), "### Merging this branch will decrease overall coverage regression\n"))
+	assert.NotContains(t, report.Markdown(), ":thumbsdown:")
+
+	report.Scoring = GradePolicy
+	assert.True(t, strings.HasPrefix(report.Markdown(), "### Merging this branch will decrease overall coverage A\n"))
+	assert.Contains(t, report.Markdown(), "| prioqueue | 90.20% (▼ 9.80 pp) | A |\n")
+
+	report.Full = true
+	report.Scoring = nil
+	assert.True(t, strings.HasPrefix(report.Markdown(), "### The coverage decreased in 1 package\n"))
+}
+
+func TestComparison_Markdown_Accessible(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	c, err := NewComparison([]string{"old", "new"}, []*coverage.Coverage{oldCov, newCov}, "old")
+	require.NoError(t, err)
+	c.TrimPrefix("github.com/fgrosse/")
+	c.Accessible = true
+
+	assert.Contains(t, c.Markdown(), "| Total | 100.00% | 90.20% (▼ 9.80 pp) regression |")
+	assert.NotContains(t, c.Markdown(), "**")
+}
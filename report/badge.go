@@ -0,0 +1,134 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// BadgeColor is the color of a coverage badge for all percentages of at
+// least Min.
+type BadgeColor struct {
+	Min   float64
+	Color string // hex color (e.g. "#4c1") or shields color name (e.g. "green")
+}
+
+// DefaultBadgeColors are the color buckets that are used for coverage badges
+// unless configured otherwise.
+var DefaultBadgeColors = []BadgeColor{
+	{Min: 90, Color: "brightgreen"},
+	{Min: 80, Color: "green"},
+	{Min: 70, Color: "yellowgreen"},
+	{Min: 60, Color: "yellow"},
+	{Min: 50, Color: "orange"},
+	{Min: 0, Color: "red"},
+}
+
+// badgeColorNames maps the named colors of shields.io to their hex values.
+var badgeColorNames = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+}
+
+// ParseBadgeColors parses color buckets in the format "90:brightgreen,75:#dfb317,0:red".
+func ParseBadgeColors(s string) ([]BadgeColor, error) {
+	var colors []BadgeColor
+	for _, part := range strings.Split(s, ",") {
+		minimum, color, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || color == "" {
+			return nil, fmt.Errorf("invalid badge color %q: expected min:color", part)
+		}
+
+		percent, err := strconv.ParseFloat(minimum, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid badge color %q: %w", part, err)
+		}
+
+		if _, ok := badgeColorNames[color]; !ok && !strings.HasPrefix(color, "#") {
+			return nil, fmt.Errorf("invalid badge color %q: unknown color %q", part, color)
+		}
+
+		colors = append(colors, BadgeColor{Min: percent, Color: color})
+	}
+
+	return colors, nil
+}
+
+// FormatBadgeColors formats color buckets in the format that is parsed by
+// ParseBadgeColors.
+func FormatBadgeColors(colors []BadgeColor) string {
+	parts := make([]string, len(colors))
+	for i, c := range colors {
+		parts[i] = strconv.FormatFloat(c.Min, 'f', -1, 64) + ":" + c.Color
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// BadgeColorFor returns the hex color of the bucket with the largest Min
+// that is less than or equal to percent. If no bucket matches, grey is
+// returned.
+func BadgeColorFor(percent float64, colors []BadgeColor) string {
+	color, best := "lightgrey", -1.0
+	for _, c := range colors {
+		if percent >= c.Min && c.Min > best {
+			color, best = c.Color, c.Min
+		}
+	}
+
+	if hex, ok := badgeColorNames[color]; ok {
+		return hex
+	}
+
+	return color
+}
+
+// Badge renders a shields.io style SVG badge that shows the label and the
+// coverage percentage with the color of its bucket.
+func Badge(label string, percent float64, colors []BadgeColor) string {
+	value := formatPercent(percent)
+	color := BadgeColorFor(round(percent, 2), colors)
+
+	labelWidth := badgeTextWidth(label) + 10
+	valueWidth := badgeTextWidth(value) + 10
+	width := labelWidth + valueWidth
+
+	label, value, color = html.EscapeString(label), html.EscapeString(value), html.EscapeString(color)
+
+	svg := new(strings.Builder)
+	fmt.Fprintf(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, width, label, value)
+	fmt.Fprintf(svg, `<title>%s: %s</title>`, label, value)
+	svg.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/>`)
+	svg.WriteString(`<stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(svg, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, width)
+	fmt.Fprintf(svg, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/>`, labelWidth)
+	fmt.Fprintf(svg, `<rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		labelWidth, valueWidth, color, width)
+	svg.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	writeBadgeText(svg, float64(labelWidth)/2, label)
+	writeBadgeText(svg, float64(labelWidth)+float64(valueWidth)/2, value)
+	svg.WriteString(`</g></svg>` + "\n")
+
+	return svg.String()
+}
+
+// writeBadgeText writes the text centered at x together with its shadow.
+func writeBadgeText(svg *strings.Builder, x float64, text string) {
+	fmt.Fprintf(svg, `<text x="%.1f" y="15" fill="#010101" fill-opacity=".3">%s</text>`, x, text)
+	fmt.Fprintf(svg, `<text x="%.1f" y="14">%s</text>`, x, text)
+}
+
+// badgeTextWidth estimates the width of the text in pixels when it is
+// rendered in 11px Verdana. Exact font metrics are not needed since the
+// text is centered within its box.
+func badgeTextWidth(text string) int {
+	return utf8.RuneCountInString(text) * 7
+}
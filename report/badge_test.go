@@ -0,0 +1,58 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBadgeColorFor(t *testing.T) {
+	cases := map[float64]string{
+		100:   "#4c1",
+		90:    "#4c1",
+		89.99: "#97ca00",
+		65:    "#dfb317",
+		0:     "#e05d44",
+	}
+
+	for percent, expected := range cases {
+		assert.Equal(t, expected, BadgeColorFor(percent, DefaultBadgeColors), "percent %v", percent)
+	}
+
+	assert.Equal(t, "#9f9f9f", BadgeColorFor(10, []BadgeColor{{Min: 50, Color: "green"}}))
+}
+
+func TestParseBadgeColors(t *testing.T) {
+	colors, err := ParseBadgeColors("80:green, 50:#ff0000,0:red")
+	require.NoError(t, err)
+	assert.Equal(t, []BadgeColor{{80, "green"}, {50, "#ff0000"}, {0, "red"}}, colors)
+	assert.Equal(t, "#ff0000", BadgeColorFor(79.9, colors))
+
+	_, err = ParseBadgeColors("80:pink")
+	assert.EqualError(t, err, `invalid badge color "80:pink": unknown color "pink"`)
+
+	_, err = ParseBadgeColors("green")
+	assert.EqualError(t, err, `invalid badge color "green": expected min:color`)
+}
+
+func TestFormatBadgeColors(t *testing.T) {
+	s := FormatBadgeColors(DefaultBadgeColors)
+	assert.Equal(t, "90:brightgreen,80:green,70:yellowgreen,60:yellow,50:orange,0:red", s)
+
+	colors, err := ParseBadgeColors(s)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBadgeColors, colors)
+	assert.Equal(t, "75.5:#dfb317", FormatBadgeColors([]BadgeColor{{75.5, "#dfb317"}}))
+}
+
+func TestBadge(t *testing.T) {
+	svg := Badge("coverage", 85.123, DefaultBadgeColors)
+
+	assert.Contains(t, svg, `aria-label="coverage: 85.12%"`)
+	assert.Contains(t, svg, `<rect x="66" width="52" height="20" fill="#97ca00"/>`)
+	assert.Contains(t, svg, `<text x="33.0" y="14">coverage</text>`)
+	assert.Contains(t, svg, `<text x="92.0" y="14">85.12%</text>`)
+
+	assert.Contains(t, Badge("a&b", 50, DefaultBadgeColors), "<title>a&amp;b: 50.00%</title>")
+}
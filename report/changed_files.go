@@ -0,0 +1,36 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ParseChangedFiles reads the JSON string array of changed files from the
+// given file and adds the prefix to each of them.
+func ParseChangedFiles(filename, prefix string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadChangedFiles(f, prefix)
+}
+
+// ReadChangedFiles reads a JSON string array of changed files from rd and
+// adds the prefix to each of them.
+func ReadChangedFiles(rd io.Reader, prefix string) ([]string, error) {
+	var files []string
+	err := json.NewDecoder(rd).Decode(&files)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, file := range files {
+		files[i] = filepath.Join(prefix, file)
+	}
+
+	return files, nil
+}
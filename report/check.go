@@ -0,0 +1,50 @@
+package report
+
+import (
+	"fmt"
+)
+
+// Gates configures the conditions that are verified by Report.Check.
+// The MinCoverage and MinPackageCoverage gates are disabled when they are
+// zero and the MaxDecrease gate is disabled when it is negative.
+type Gates struct {
+	// MinCoverage is the minimum total coverage of the new profile in percent.
+	MinCoverage float64
+	// MinPackageCoverage is the minimum coverage of each changed package in percent.
+	MinPackageCoverage float64
+	// MaxDecrease is the maximum coverage decrease of each changed package
+	// in percentage points.
+	MaxDecrease float64
+}
+
+// Check verifies the gates and returns a human-readable description of
+// each violation. An empty result means that all gates passed.
+func (r *Report) Check(g Gates) []string {
+	var violations []string
+
+	if g.MinCoverage > 0 {
+		if total := round(r.New.Percent(), 2); total < g.MinCoverage {
+			violations = append(violations, fmt.Sprintf(
+				"total coverage %.2f%% is below the minimum of %.2f%%", total, g.MinCoverage,
+			))
+		}
+	}
+
+	for _, pkg := range r.TemplateData().Packages {
+		newP := round(pkg.New.Percent, 2)
+		if g.MinPackageCoverage > 0 && newP < g.MinPackageCoverage {
+			violations = append(violations, fmt.Sprintf(
+				"coverage of package %s is %.2f%% which is below the minimum of %.2f%%", pkg.Name, newP, g.MinPackageCoverage,
+			))
+		}
+
+		decrease := round(round(pkg.Old.Percent, 2)-newP, 2)
+		if g.MaxDecrease >= 0 && decrease > g.MaxDecrease {
+			violations = append(violations, fmt.Sprintf(
+				"coverage of package %s decreased by %.2f percentage points which is more than the allowed %.2f", pkg.Name, decrease, g.MaxDecrease,
+			))
+		}
+	}
+
+	return violations
+}
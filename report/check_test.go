@@ -0,0 +1,56 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Check(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/01-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, changedFiles)
+
+	cases := map[string]struct {
+		gates    Gates
+		expected []string
+	}{
+		"disabled": {
+			gates: Gates{MaxDecrease: -1},
+		},
+		"all passed": {
+			gates: Gates{MinCoverage: 90, MaxDecrease: 9.8},
+		},
+		"min coverage": {
+			gates:    Gates{MinCoverage: 95, MaxDecrease: -1},
+			expected: []string{"total coverage 90.20% is below the minimum of 95.00%"},
+		},
+		"min package coverage": {
+			gates: Gates{MinPackageCoverage: 50, MaxDecrease: -1},
+			expected: []string{
+				"coverage of package github.com/fgrosse/prioqueue/foo/bar is 0.00% which is below the minimum of 50.00%",
+			},
+		},
+		"max decrease": {
+			gates: Gates{MaxDecrease: 5},
+			expected: []string{
+				"coverage of package github.com/fgrosse/prioqueue decreased by 9.80 percentage points which is more than the allowed 5.00",
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.expected, report.Check(c.gates))
+		})
+	}
+}
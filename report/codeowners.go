@@ -0,0 +1,217 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// CodeOwners maps files to their owners using the rules of a GitHub
+// CODEOWNERS file. The last matching rule of a file determines its owners.
+type CodeOwners struct {
+	// Root is the import path of the repository root (e.g. "github.com/fgrosse/example").
+	// It is trimmed from the file names of the report before they are matched
+	// against the rules, which are relative to the repository root.
+	Root string
+
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// OwnerCoverage is the coverage of the files of a single code owner.
+type OwnerCoverage struct {
+	Owner string
+	// Total is the combined coverage of all files of the owner in the old
+	// and new profiles, regardless of whether they were changed.
+	Total CoverageDelta
+	// Files contains the changed non-test files of the owner sorted like the
+	// CodeFiles of the TemplateData.
+	Files []CoverageDelta
+}
+
+// ParseCodeOwnersFile reads the CODEOWNERS file with the given name.
+func ParseCodeOwnersFile(filename string) (*CodeOwners, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadCodeOwners(f)
+}
+
+// ReadCodeOwners reads a CODEOWNERS file from r. Each line contains a
+// gitignore style pattern followed by the owners of the matching files.
+// A pattern without owners removes the owners of the matching files.
+// Empty lines and comments starting with "#" are ignored.
+func ReadCodeOwners(r io.Reader) (*CodeOwners, error) {
+	c := new(CodeOwners)
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text, _, _ := strings.Cut(s.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern, err := globPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q: %w", line, fields[0], err)
+		}
+
+		c.rules = append(c.rules, codeOwnersRule{pattern: pattern, owners: fields[1:]})
+	}
+
+	return c, s.Err()
+}
+
+// globPattern converts a gitignore style pattern into a regular
+// expression. Patterns that contain a slash (except at the end) are
+// relative to the repository root while all other patterns match at any
+// depth. A pattern that matches a directory also matches all its files,
+// except for patterns ending in "/*" which only match the direct children
+// of the directory (e.g. "docs/*" matches "docs/a.md" but not "docs/a/b.md").
+func globPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	childrenOnly := strings.HasSuffix(pattern, "/*")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	expr := new(strings.Builder)
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	switch {
+	case dirOnly:
+		expr.WriteString("/.*$")
+	case childrenOnly:
+		expr.WriteString("$")
+	default:
+		expr.WriteString("(/.*)?$")
+	}
+
+	return regexp.Compile(expr.String())
+}
+
+// Owners returns the owners of the given file of the report or nil if the
+// file has no owners.
+func (c *CodeOwners) Owners(file string) []string {
+	if c.Root != "" {
+		file = strings.TrimPrefix(coverage.TrimPathPrefix(file, c.Root), "./")
+	}
+
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(file) {
+			return c.rules[i].owners
+		}
+	}
+
+	return nil
+}
+
+// packageOwners returns the sorted owners of all changed files of the package.
+func (r *Report) packageOwners(pkg string) []string {
+	var owners []string
+	for _, f := range r.ChangedFiles {
+		if filepath.Dir(f) != pkg {
+			continue
+		}
+
+		for _, o := range r.CodeOwners.Owners(f) {
+			if !slices.Contains(owners, o) {
+				owners = append(owners, o)
+			}
+		}
+	}
+
+	slices.Sort(owners)
+	return owners
+}
+
+// owners groups the given changed files by their owners. It returns nil if
+// the report has no CodeOwners.
+func (r *Report) owners(files []CoverageDelta) []OwnerCoverage {
+	if r.CodeOwners == nil {
+		return nil
+	}
+
+	oldTotals := r.ownerTotals(r.Old)
+	newTotals := r.ownerTotals(r.New)
+
+	byOwner := map[string]*OwnerCoverage{}
+	for _, f := range files {
+		for _, o := range f.Owners {
+			if byOwner[o] == nil {
+				byOwner[o] = &OwnerCoverage{
+					Owner: o,
+					Total: r.newCoverageDelta(o, oldTotals[o], newTotals[o]),
+				}
+			}
+			byOwner[o].Files = append(byOwner[o].Files, f)
+		}
+	}
+
+	result := make([]OwnerCoverage, 0, len(byOwner))
+	for _, o := range sortedKeys(byOwner) {
+		result = append(result, *byOwner[o])
+	}
+
+	return result
+}
+
+// ownerTotals sums up the statements of all files of cov per owner.
+func (r *Report) ownerTotals(cov *coverage.Coverage) map[string]CoverageValues {
+	totals := map[string]CoverageValues{}
+	for name, p := range cov.Files {
+		for _, o := range r.CodeOwners.Owners(name) {
+			v := totals[o]
+			v.Total += p.GetTotal()
+			v.Covered += p.GetCovered()
+			v.Missed += p.GetMissed()
+			totals[o] = v
+		}
+	}
+
+	for o, v := range totals {
+		if v.Total > 0 {
+			v.Percent = float64(v.Covered) / float64(v.Total) * 100
+			totals[o] = v
+		}
+	}
+
+	return totals
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeOwners_Owners(t *testing.T) {
+	owners, err := ReadCodeOwners(strings.NewReader(`
+# default owners
+*                    @org/core
+*.md                 @org/docs
+/internal/storage/   @org/storage # comment
+internal/storage/kv  @org/kv @alice
+**/testdata/**       @org/qa
+/cmd/*.go            @org/cli
+/cmd/tool/main.go
+`))
+	require.NoError(t, err)
+
+	cases := map[string][]string{
+		"main.go":                          {"@org/core"},
+		"docs/README.md":                   {"@org/docs"},
+		"internal/storage/sql/sql.go":      {"@org/storage"},
+		"internal/storage/kv/kv.go":        {"@org/kv", "@alice"},
+		"internal/storage/kv/testdata/a.x": {"@org/qa"},
+		"cmd/main.go":                      {"@org/cli"},
+		"cmd/tool/tool.go":                 {"@org/core"},
+		"cmd/tool/main.go":                 {},
+		"foo/internal/storage/x.go":        {"@org/core"},
+	}
+
+	for file, expected := range cases {
+		assert.ElementsMatch(t, expected, owners.Owners(file), file)
+	}
+
+	owners.Root = "example.com/repo"
+	assert.Equal(t, []string{"@org/cli"}, owners.Owners("example.com/repo/cmd/main.go"))
+
+	_, err = ReadCodeOwners(strings.NewReader("/ @org/core\n"))
+	assert.EqualError(t, err, `line 1: invalid pattern "/": empty pattern`)
+}
+
+func TestCodeOwners_Owners_DirectChildren(t *testing.T) {
+	owners, err := ReadCodeOwners(strings.NewReader(`
+*         @org/core
+/docs/*   @org/docs
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"@org/docs"}, owners.Owners("docs/a.go"))
+	assert.Equal(t, []string{"@org/core"}, owners.Owners("docs/a/b.go"))
+}
+
+func TestReport_Markdown_CodeOwners(t *testing.T) {
+	oldCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/repo/a/a.go:1.1,2.2 1 0
+example.com/repo/a/a.go:3.1,4.2 1 1
+example.com/repo/a/other.go:1.1,2.2 2 1
+example.com/repo/b/b.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/repo/a/a.go:1.1,2.2 1 1
+example.com/repo/a/a.go:3.1,4.2 1 1
+example.com/repo/a/other.go:1.1,2.2 2 1
+example.com/repo/b/b.go:1.1,2.2 1 0
+`), nil)
+	require.NoError(t, err)
+
+	owners, err := ReadCodeOwners(strings.NewReader("/a/ @org/a\n/b/ @org/b\n"))
+	require.NoError(t, err)
+	owners.Root = "example.com/repo"
+
+	report := New(oldCov, newCov, []string{"example.com/repo/a/a.go", "example.com/repo/b/b.go"})
+	report.CodeOwners = owners
+	report.TrimPrefix("example.com/")
+
+	expected := "### Coverage by code owner\n" +
+		"\n" +
+		"#### @org/a\n" +
+		"\n" +
+		"| Changed File | Coverage Δ | :robot: |\n" +
+		"|--------------|------------|---------|\n" +
+		"| repo/a/a.go | 100.00% (**+50.00%**) | :star2: |\n" +
+		"| **Total** | 100.00% (**+25.00%**) | :star2: |\n" +
+		"\n" +
+		"#### @org/b\n" +
+		"\n" +
+		"| Changed File | Coverage Δ | :robot: |\n" +
+		"|--------------|------------|---------|\n" +
+		"| repo/b/b.go | 0.00% (**-100.00%**) | :skull: :skull: :skull: :skull: :skull:  |\n" +
+		"| **Total** | 0.00% (**-100.00%**) | :skull: :skull: :skull: :skull: :skull:  |\n" +
+		"\n" +
+		"---\n"
+	assert.Contains(t, report.Markdown(), expected)
+
+	doc := report.JSONReport()
+	require.Len(t, doc.Owners, 2)
+	assert.Equal(t, "@org/a", doc.Owners[0].Owner)
+	assert.Equal(t, 25.0, doc.Owners[0].Delta)
+	assert.Equal(t, []string{"repo/a/a.go"}, doc.Owners[0].Files)
+	assert.Equal(t, []string{"@org/a"}, doc.Packages[0].Owners)
+	assert.Equal(t, []string{"@org/b"}, doc.Files[1].Owners)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, report.WriteMetrics(buf))
+	assert.Contains(t, buf.String(), `owner_coverage={"@org/a":100,"@org/b":0}`+"\n")
+	assert.Contains(t, buf.String(), `owner_coverage_delta={"@org/a":25,"@org/b":-100}`+"\n")
+}
@@ -0,0 +1,200 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// Comparison compares the coverage of two or more named profiles (e.g. of
+// different Go versions or feature flags) side by side. All deltas are
+// relative to the reference profile.
+type Comparison struct {
+	Names     []string
+	Coverages []*coverage.Coverage
+	Reference int // index of the reference profile in Names and Coverages
+
+	// Accessible renders the deltas with arrows and words instead of emojis
+	// and Markdown emphasis (see Report.Accessible).
+	Accessible bool
+}
+
+// JSONComparison is the JSON document of a Comparison.
+type JSONComparison struct {
+	SchemaVersion int                     `json:"schema_version"`
+	Reference     string                  `json:"reference"`
+	Profiles      []string                `json:"profiles"`
+	Total         []JSONComparisonValues  `json:"total"`
+	Packages      []JSONComparisonPackage `json:"packages"`
+}
+
+// JSONComparisonPackage contains the coverage of a single package in each
+// profile of a Comparison.
+type JSONComparisonPackage struct {
+	Name     string                 `json:"name"`
+	Coverage []JSONComparisonValues `json:"coverage"`
+}
+
+// JSONComparisonValues contains the coverage in a single profile of a
+// Comparison and its delta relative to the reference profile.
+type JSONComparisonValues struct {
+	Profile string `json:"profile"`
+	JSONValues
+	Delta  float64 `json:"delta"`
+	Status string  `json:"status"`
+}
+
+// NewComparison creates a Comparison of the given named profiles. The
+// reference must be one of the names.
+func NewComparison(names []string, covs []*coverage.Coverage, reference string) (*Comparison, error) {
+	if len(names) != len(covs) {
+		return nil, fmt.Errorf("got %d names for %d profiles", len(names), len(covs))
+	}
+
+	ref := slices.Index(names, reference)
+	if ref < 0 {
+		return nil, fmt.Errorf("reference %q is not one of the compared profiles", reference)
+	}
+
+	return &Comparison{Names: names, Coverages: covs, Reference: ref}, nil
+}
+
+// TrimPrefix removes the given prefix from all file names of all profiles.
+func (c *Comparison) TrimPrefix(prefix string) {
+	for _, cov := range c.Coverages {
+		cov.TrimPrefix(prefix)
+	}
+}
+
+// Markdown renders the comparison as Markdown table with one row per package
+// and one column per profile.
+func (c *Comparison) Markdown() string {
+	report := new(strings.Builder)
+
+	fmt.Fprintf(report, "### Coverage comparison (reference: %s)\n", c.Names[c.Reference])
+	fmt.Fprintln(report)
+	fmt.Fprintf(report, "| Package | %s |\n", strings.Join(c.Names, " | "))
+	fmt.Fprintf(report, "|---------|%s\n", strings.Repeat("----------|", len(c.Names)))
+
+	names, rows := c.packageRows()
+	for i, name := range names {
+		fmt.Fprintf(report, "| %s |", name)
+		for j, d := range rows[i] {
+			fmt.Fprintf(report, " %s |", c.markdownCell(j, d))
+		}
+		fmt.Fprintln(report)
+	}
+
+	if c.Accessible {
+		fmt.Fprint(report, "| Total |")
+	} else {
+		fmt.Fprint(report, "| **Total** |")
+	}
+	for j, d := range c.row(c.Coverages) {
+		fmt.Fprintf(report, " %s |", c.markdownCell(j, d))
+	}
+
+	return report.String()
+}
+
+func (c *Comparison) markdownCell(i int, d CoverageDelta) string {
+	if i == c.Reference {
+		return formatPercent(d.New.Percent)
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%s (%s) %s", formatPercent(d.New.Percent), d.DeltaText, d.Emoji))
+}
+
+// JSONReport returns the JSON document of the comparison.
+func (c *Comparison) JSONReport() JSONComparison {
+	doc := JSONComparison{
+		SchemaVersion: JSONSchemaVersion,
+		Reference:     c.Names[c.Reference],
+		Profiles:      c.Names,
+		Total:         c.jsonRow(c.row(c.Coverages)),
+	}
+
+	names, rows := c.packageRows()
+	doc.Packages = make([]JSONComparisonPackage, 0, len(names))
+	for i, name := range names {
+		doc.Packages = append(doc.Packages, JSONComparisonPackage{
+			Name:     name,
+			Coverage: c.jsonRow(rows[i]),
+		})
+	}
+
+	return doc
+}
+
+// JSON returns the JSON document of the comparison as string.
+func (c *Comparison) JSON() string {
+	data, err := json.MarshalIndent(c.JSONReport(), "", "    ")
+	if err != nil {
+		panic(err) // should never happen
+	}
+
+	return string(data)
+}
+
+func (c *Comparison) jsonRow(row []CoverageDelta) []JSONComparisonValues {
+	values := make([]JSONComparisonValues, len(row))
+	for i, d := range row {
+		values[i] = JSONComparisonValues{
+			Profile:    c.Names[i],
+			JSONValues: jsonValues(d.New),
+			Delta:      round(d.Delta, 2),
+			Status:     d.Status,
+		}
+	}
+
+	return values
+}
+
+// packageRows returns the sorted names of all packages that exist in any of
+// the profiles together with the coverage deltas of each package. A package
+// that is missing in a profile is treated as if it had no statements.
+func (c *Comparison) packageRows() (names []string, rows [][]CoverageDelta) {
+	all := map[string]bool{}
+	pkgs := make([]map[string]*coverage.Coverage, len(c.Coverages))
+	for i, cov := range c.Coverages {
+		pkgs[i] = cov.ByPackage()
+		for name := range pkgs[i] {
+			all[name] = true
+		}
+	}
+
+	names = sortedKeys(all)
+	rows = make([][]CoverageDelta, len(names))
+	for i, name := range names {
+		covs := make([]*coverage.Coverage, len(pkgs))
+		for j := range pkgs {
+			covs[j] = pkgs[j][name]
+		}
+		rows[i] = c.row(covs)
+	}
+
+	return names, rows
+}
+
+func (c *Comparison) scoring() ScoringPolicy {
+	if c.Accessible {
+		return AccessiblePolicy
+	}
+
+	return DefaultScoringPolicy
+}
+
+// row returns the delta of each of the given coverages relative to the
+// coverage at the reference index.
+func (c *Comparison) row(covs []*coverage.Coverage) []CoverageDelta {
+	ref := coverageValues(covs[c.Reference])
+	row := make([]CoverageDelta, len(covs))
+	for i, cov := range covs {
+		row[i] = scoredCoverageDelta(c.scoring(), c.Accessible, c.Names[i], ref, coverageValues(cov))
+	}
+
+	return row
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestComparison(t *testing.T, reference string) *Comparison {
+	t.Helper()
+
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	c, err := NewComparison([]string{"go1.21", "go1.22", "go1.23"}, []*coverage.Coverage{oldCov, newCov, oldCov}, reference)
+	require.NoError(t, err)
+
+	c.TrimPrefix("github.com/fgrosse/")
+	return c
+}
+
+func TestComparison_Markdown(t *testing.T) {
+	actual := newTestComparison(t, "go1.21").Markdown()
+
+	expected := "### Coverage comparison (reference: go1.21)\n" +
+		"\n" +
+		"| Package | go1.21 | go1.22 | go1.23 |\n" +
+		"|---------|----------|----------|----------|\n" +
+		"| prioqueue | 100.00% | 90.20% (**-9.80%**) :thumbsdown: | 100.00% (ø) |\n" +
+		"| **Total** | 100.00% | 90.20% (**-9.80%**) :thumbsdown: | 100.00% (ø) |"
+	assert.Equal(t, expected, actual)
+}
+
+func TestComparison_JSON(t *testing.T) {
+	doc := newTestComparison(t, "go1.22").JSONReport()
+
+	assert.Equal(t, "go1.22", doc.Reference)
+	assert.Equal(t, []string{"go1.21", "go1.22", "go1.23"}, doc.Profiles)
+	require.Len(t, doc.Total, 3)
+	require.Len(t, doc.Packages, 1)
+	assert.Equal(t, "prioqueue", doc.Packages[0].Name)
+
+	pkg := doc.Packages[0].Coverage
+	assert.Equal(t, "go1.21", pkg[0].Profile)
+	assert.Equal(t, 9.8, pkg[0].Delta)
+	assert.Equal(t, "increased", pkg[0].Status)
+	assert.Equal(t, 0.0, pkg[1].Delta)
+	assert.Equal(t, "unchanged", pkg[1].Status)
+	assert.Equal(t, 90.2, pkg[1].Percent)
+}
+
+func TestNewComparison_UnknownReference(t *testing.T) {
+	_, err := NewComparison([]string{"a", "b"}, []*coverage.Coverage{coverage.New(nil), coverage.New(nil)}, "c")
+	assert.EqualError(t, err, `reference "c" is not one of the compared profiles`)
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Component is a named group of files (e.g. "api" or "storage") that is
+// independent of the package structure. Its coverage is the combined
+// coverage of all files that match at least one of its Patterns.
+type Component struct {
+	Name string
+	// Patterns are gitignore style patterns (e.g. "internal/storage/**" or
+	// "*_handler.go") that are matched against the file names of the report.
+	Patterns []string
+
+	patterns []*regexp.Regexp
+}
+
+// NewComponent creates a Component with the given name and patterns.
+func NewComponent(name string, patterns ...string) (Component, error) {
+	c := Component{Name: name, Patterns: patterns}
+	for _, p := range patterns {
+		expr, err := globPattern(p)
+		if err != nil {
+			return Component{}, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		c.patterns = append(c.patterns, expr)
+	}
+
+	return c, nil
+}
+
+// ParseComponentsFile reads the components file with the given name.
+func ParseComponentsFile(filename string) ([]Component, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadComponents(f)
+}
+
+// ReadComponents reads a components file from r. Each line contains the
+// name of a component followed by one or more patterns separated by
+// whitespace. Empty lines and lines starting with "#" are ignored.
+func ReadComponents(r io.Reader) ([]Component, error) {
+	var components []Component
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected component name and patterns but got %q", line, text)
+		}
+
+		for _, c := range components {
+			if c.Name == fields[0] {
+				return nil, fmt.Errorf("line %d: duplicate component %q", line, fields[0])
+			}
+		}
+
+		c, err := NewComponent(fields[0], fields[1:]...)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		components = append(components, c)
+	}
+
+	return components, s.Err()
+}
+
+// Match returns true if the file matches at least one pattern of the component.
+func (c Component) Match(file string) bool {
+	for _, p := range c.patterns {
+		if p.MatchString(file) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// components returns the coverage of each component of the report in the
+// order in which they were configured.
+func (r *Report) components() []CoverageDelta {
+	result := make([]CoverageDelta, 0, len(r.Components))
+	for _, c := range r.Components {
+		result = append(result, r.newCoverageDelta(c.Name, filesValues(r.Old, c.Match), filesValues(r.New, c.Match)))
+	}
+
+	return result
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadComponents(t *testing.T) {
+	components, err := ReadComponents(strings.NewReader(`
+# name   patterns
+api      api/** internal/http/**
+storage  internal/storage/
+`))
+	require.NoError(t, err)
+	require.Len(t, components, 2)
+	assert.Equal(t, "api", components[0].Name)
+	assert.Equal(t, []string{"api/**", "internal/http/**"}, components[0].Patterns)
+
+	assert.True(t, components[0].Match("api/v1/handler.go"))
+	assert.True(t, components[0].Match("internal/http/server.go"))
+	assert.False(t, components[0].Match("internal/storage/sql.go"))
+	assert.True(t, components[1].Match("internal/storage/kv/kv.go"))
+
+	handlers, err := NewComponent("handlers", "api/*")
+	require.NoError(t, err)
+	assert.True(t, handlers.Match("api/handler.go"))
+	assert.False(t, handlers.Match("api/v1/handler.go"))
+
+	_, err = ReadComponents(strings.NewReader("api\n"))
+	assert.EqualError(t, err, `line 1: expected component name and patterns but got "api"`)
+
+	_, err = ReadComponents(strings.NewReader("api a/**\napi b/**\n"))
+	assert.EqualError(t, err, `line 2: duplicate component "api"`)
+}
+
+func TestReport_Markdown_Components(t *testing.T) {
+	oldCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/api/api.go:1.1,2.2 1 1
+example.com/api/api.go:3.1,4.2 1 0
+example.com/internal/http/server.go:1.1,2.2 2 1
+example.com/internal/storage/sql.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/api/api.go:1.1,2.2 1 1
+example.com/api/api.go:3.1,4.2 1 1
+example.com/internal/http/server.go:1.1,2.2 2 1
+example.com/internal/storage/sql.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	api, err := NewComponent("api", "api/**", "internal/http/**")
+	require.NoError(t, err)
+	storage, err := NewComponent("storage", "internal/storage/")
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, []string{"example.com/api/api.go"})
+	report.TrimPrefix("example.com/")
+	report.Components = []Component{api, storage}
+
+	expected := "### Components\n" +
+		"\n" +
+		"| Component | Coverage Δ | Total | Covered | Missed | :robot: |\n" +
+		"|-----------|------------|-------|---------|--------|---------|\n" +
+		"| api | 100.00% (**+25.00%**) | 4 | 4 (+1) | 0 (-1) | :star2: |\n" +
+		"| storage | 100.00% (ø) | 1 | 1 | 0 |  |\n" +
+		"\n" +
+		"---\n"
+	assert.Contains(t, report.Markdown(), expected)
+
+	doc := report.JSONReport()
+	require.Len(t, doc.Components, 2)
+	assert.Equal(t, 25.0, doc.Components[0].Delta)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, report.WriteMetrics(buf))
+	assert.Contains(t, buf.String(), `component_coverage={"api":100,"storage":100}`+"\n")
+	assert.Contains(t, buf.String(), `component_coverage_delta={"api":25,"storage":0}`+"\n")
+}
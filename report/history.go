@@ -0,0 +1,215 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// HistoryEntry is the coverage summary of a single commit as it is stored
+// in a history file. A history file contains one JSON encoded entry per line
+// in the order in which they were recorded.
+type HistoryEntry struct {
+	Commit    string                `json:"commit"`
+	Timestamp time.Time             `json:"timestamp"`
+	Total     JSONValues            `json:"total"`
+	Packages  map[string]JSONValues `json:"packages"`
+}
+
+// HistoryTrend is the coverage trend of the changed packages and of the
+// total coverage in the history of a report.
+type HistoryTrend struct {
+	// Commits is the number of history entries.
+	Commits  int
+	Total    PackageHistory
+	Packages []PackageHistory
+}
+
+// PackageHistory is the coverage trend of a package (or of the total
+// coverage) in the history of a report.
+type PackageHistory struct {
+	// Name is the package name or "" for the total coverage.
+	Name string
+	// Sparkline shows the coverage of each history entry followed by the
+	// new coverage of the report (e.g. "▁▃▅█"). Entries in which the package
+	// did not exist are shown as space.
+	Sparkline string
+	// Best and Worst are the history entries with the highest and lowest
+	// coverage of the package. They are nil if the package has no history.
+	Best, Worst *HistoryPoint
+}
+
+// HistoryPoint is the coverage of a package in a single history entry.
+type HistoryPoint struct {
+	Commit  string
+	Percent float64
+}
+
+// NewHistoryEntry creates the history entry of the given coverage.
+func NewHistoryEntry(commit string, timestamp time.Time, cov *coverage.Coverage) HistoryEntry {
+	entry := HistoryEntry{
+		Commit:    commit,
+		Timestamp: timestamp,
+		Total:     jsonValues(coverageValues(cov)),
+		Packages:  map[string]JSONValues{},
+	}
+
+	for name, pkg := range cov.ByPackage() {
+		entry.Packages[name] = jsonValues(coverageValues(pkg))
+	}
+
+	return entry
+}
+
+// AppendHistory appends the entry to the history file with the given name.
+// The file is created if it does not exist yet.
+func AppendHistory(filename string, entry HistoryEntry) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = WriteHistory(f, entry)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// WriteHistory writes the entry as a single line of JSON to w.
+func WriteHistory(w io.Writer, entry HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// ParseHistoryFile reads all entries of the history file with the given name.
+func ParseHistoryFile(filename string) ([]HistoryEntry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadHistory(f)
+}
+
+// ReadHistory reads all entries of a history file from rd. Empty lines are
+// ignored.
+func ReadHistory(rd io.Reader) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	s := bufio.NewScanner(rd)
+	s.Buffer(nil, 16*1024*1024) // entries of large repositories can exceed the default limit
+	for line := 1; s.Scan(); line++ {
+		if strings.TrimSpace(s.Text()) == "" {
+			continue
+		}
+
+		var entry HistoryEntry
+		if err := json.Unmarshal(s.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid history entry in line %d: %w", line, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, s.Err()
+}
+
+// LastHistory returns the last n entries of the history. A negative n
+// returns no entries.
+func LastHistory(entries []HistoryEntry, n int) []HistoryEntry {
+	return entries[len(entries)-min(max(n, 0), len(entries)):]
+}
+
+func (r *Report) trimHistoryPrefix(prefix string) {
+	for i, entry := range r.History {
+		packages := make(map[string]JSONValues, len(entry.Packages))
+		for name, values := range entry.Packages {
+			packages[coverage.TrimPathPrefix(name, prefix)] = values
+		}
+		r.History[i].Packages = packages
+	}
+}
+
+// packageHistory returns the trend of the package with the given name in
+// the history of the report. An empty name selects the total coverage.
+func (r *Report) packageHistory(name string, newPercent float64) PackageHistory {
+	h := PackageHistory{Name: name}
+
+	var values []float64
+	for _, entry := range r.History {
+		v, ok := entry.Total, true
+		if name != "" {
+			v, ok = entry.Packages[name]
+		}
+
+		if !ok {
+			values = append(values, math.NaN())
+			continue
+		}
+
+		values = append(values, v.Percent)
+		if h.Best == nil || v.Percent > h.Best.Percent {
+			h.Best = &HistoryPoint{Commit: entry.Commit, Percent: v.Percent}
+		}
+		if h.Worst == nil || v.Percent < h.Worst.Percent {
+			h.Worst = &HistoryPoint{Commit: entry.Commit, Percent: v.Percent}
+		}
+	}
+
+	h.Sparkline = sparkline(append(values, round(newPercent, 2)))
+	return h
+}
+
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders the values as a string of block characters that are
+// scaled between the minimum and maximum value. NaN values are rendered as
+// space.
+func sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+
+	line := new(strings.Builder)
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			line.WriteRune(' ')
+		case hi == lo:
+			line.WriteRune(sparkTicks[len(sparkTicks)/2])
+		default:
+			i := int(math.Round((v - lo) / (hi - lo) * float64(len(sparkTicks)-1)))
+			line.WriteRune(sparkTicks[i])
+		}
+	}
+
+	return line.String()
+}
+
+// shortCommit abbreviates a commit SHA to the length that is used by git
+// and GitHub by default.
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+
+	return commit
+}
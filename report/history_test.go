@@ -0,0 +1,82 @@
+package report
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_WriteAndRead(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteHistory(buf, NewHistoryEntry("aaaaaaaaaa", ts, oldCov)))
+	require.NoError(t, WriteHistory(buf, NewHistoryEntry("bbbbbbbbbb", ts.Add(time.Hour), newCov)))
+
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+
+	entries, err := ReadHistory(buf)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "aaaaaaaaaa", entries[0].Commit)
+	assert.Equal(t, ts, entries[0].Timestamp)
+	assert.Equal(t, 100.0, entries[0].Total.Percent)
+	assert.Equal(t, JSONValues{Percent: 90.2, TotalStatements: 102, CoveredStatements: 92, MissedStatements: 10}, entries[1].Packages["github.com/fgrosse/prioqueue"])
+
+	assert.Equal(t, entries[1:], LastHistory(entries, 1))
+	assert.Equal(t, entries, LastHistory(entries, 10))
+	assert.Empty(t, LastHistory(entries, 0))
+	assert.Empty(t, LastHistory(entries, -1))
+}
+
+func TestReadHistory_Invalid(t *testing.T) {
+	_, err := ReadHistory(strings.NewReader("{}\n\nnot json\n"))
+	assert.ErrorContains(t, err, "invalid history entry in line 3")
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▆█", sparkline([]float64{10, 15, 17}))
+	assert.Equal(t, "▅▅", sparkline([]float64{50, 50}))
+	assert.Equal(t, "▁ █", sparkline([]float64{0, math.NaN(), 100}))
+}
+
+func TestReport_Markdown_History(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/01-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, changedFiles)
+	report.History = []HistoryEntry{
+		NewHistoryEntry("1111111aaa", time.Now(), newCov),
+		NewHistoryEntry("2222222bbb", time.Now(), oldCov),
+	}
+	report.TrimPrefix("github.com/fgrosse/")
+
+	expected := "### Coverage history (last 2 commits)\n" +
+		"\n" +
+		"| Package | Trend | Best | Worst |\n" +
+		"|---------|-------|------|-------|\n" +
+		"| prioqueue | ▁█▁ | 100.00% (2222222) | 90.20% (1111111) |\n" +
+		"| prioqueue/foo/bar |   ▅ | – | – |\n" +
+		"| **Total** | ▁█▁ | 100.00% (2222222) | 90.20% (1111111) |\n" +
+		"\n" +
+		"---\n"
+	assert.Contains(t, report.Markdown(), expected)
+}
@@ -0,0 +1,283 @@
+package report
+
+import (
+	"encoding/json"
+	"path"
+)
+
+// JSONSchemaVersion is the version of the JSON report document. It is only
+// incremented on breaking changes of the document structure.
+const JSONSchemaVersion = 1
+
+// JSONSchemaURL is the location of the published JSON Schema that describes
+// the JSON report document.
+const JSONSchemaURL = "https://raw.githubusercontent.com/fgrosse/go-coverage-report/main/schema/report-v1.schema.json"
+
+// JSONReport is the versioned JSON document that is produced by the "json"
+// output format. It is deliberately decoupled from the internal types so
+// that it stays stable when the internals change.
+type JSONReport struct {
+	Schema        string         `json:"$schema"`
+	SchemaVersion int            `json:"schema_version"`
+	Tool          JSONTool       `json:"tool"`
+	Inputs        JSONInputs     `json:"inputs"`
+	Summary       JSONSummary    `json:"summary"`
+	Packages      []JSONCoverage `json:"packages"`
+	Files         []JSONCoverage `json:"files"`
+	TestFiles     []string       `json:"test_files"`
+	Labels        []string       `json:"labels,omitempty"`
+	Platforms     []string       `json:"platforms,omitempty"`
+
+	IndirectPackages []JSONCoverage  `json:"indirect_packages,omitempty"`
+	Directories      []JSONDirectory `json:"directories,omitempty"`
+	Owners           []JSONOwner     `json:"owners,omitempty"`
+	Components       []JSONCoverage  `json:"components,omitempty"`
+	TopGains         []JSONCoverage  `json:"top_gains,omitempty"`
+	TopLosses        []JSONCoverage  `json:"top_losses,omitempty"`
+}
+
+// JSONTool identifies the tool that produced the JSON report.
+type JSONTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// JSONInputs describes the inputs that were used to produce the JSON report.
+type JSONInputs struct {
+	OldProfile   string        `json:"old_profile"`
+	NewProfile   string        `json:"new_profile"`
+	ChangedFiles string        `json:"changed_files"`
+	Root         string        `json:"root"`
+	Trim         string        `json:"trim"`
+	Full         bool          `json:"full,omitempty"`
+	Baseline     *JSONBaseline `json:"baseline,omitempty"`
+}
+
+// JSONBaseline describes the baseline commit of the old profile if it was
+// resolved from a baseline store.
+type JSONBaseline struct {
+	Commit    string `json:"commit"`
+	MergeBase string `json:"merge_base"`
+	Distance  int    `json:"distance"`
+}
+
+// JSONSummary contains the total coverage of the old and new profiles and
+// summarizes how the coverage of the changed packages changed.
+type JSONSummary struct {
+	Trend       string              `json:"trend"`
+	NumIncrease int                 `json:"num_increase"`
+	NumDecrease int                 `json:"num_decrease"`
+	Old         JSONValues          `json:"old"`
+	New         JSONValues          `json:"new"`
+	Delta       float64             `json:"delta"`
+	Status      string              `json:"status"`
+	Labels      []JSONLabelCoverage `json:"labels,omitempty"`
+}
+
+// JSONCoverage contains the old and new coverage of a package or file.
+type JSONCoverage struct {
+	Name      string              `json:"name"`
+	Package   string              `json:"package,omitempty"` // only set for files
+	Old       JSONValues          `json:"old"`
+	New       JSONValues          `json:"new"`
+	Delta     float64             `json:"delta"`
+	Status    string              `json:"status"`
+	Labels    []JSONLabelCoverage `json:"labels,omitempty"`    // only set for packages
+	Platforms []string            `json:"platforms,omitempty"` // only set for files
+	Owners    []string            `json:"owners,omitempty"`
+}
+
+// JSONDirectory contains the combined old and new coverage of all packages
+// in a directory and the names of the changed packages within it.
+type JSONDirectory struct {
+	JSONCoverage
+	Packages []string `json:"packages"`
+	// Subdirectories contains the combined coverage of each subdirectory
+	// with more than one changed package.
+	Subdirectories []JSONCoverage `json:"subdirectories,omitempty"`
+}
+
+// JSONOwner contains the combined old and new coverage of all files of a
+// code owner and the names of the changed files it owns.
+type JSONOwner struct {
+	Owner  string     `json:"owner"`
+	Old    JSONValues `json:"old"`
+	New    JSONValues `json:"new"`
+	Delta  float64    `json:"delta"`
+	Status string     `json:"status"`
+	Files  []string   `json:"files"`
+}
+
+// JSONLabelCoverage contains the old and new coverage of a package or the
+// summary in a labeled profile.
+type JSONLabelCoverage struct {
+	Label  string     `json:"label"`
+	Old    JSONValues `json:"old"`
+	New    JSONValues `json:"new"`
+	Delta  float64    `json:"delta"`
+	Status string     `json:"status"`
+}
+
+// JSONValues contains the statement counts and the coverage percentage of
+// a package, file or profile.
+type JSONValues struct {
+	Percent           float64 `json:"percent"`
+	TotalStatements   int64   `json:"total_statements"`
+	CoveredStatements int64   `json:"covered_statements"`
+	MissedStatements  int64   `json:"missed_statements"`
+}
+
+// JSONReport returns the versioned JSON document of the report.
+func (r *Report) JSONReport() JSONReport {
+	data := r.TemplateData()
+	doc := JSONReport{
+		Schema:        JSONSchemaURL,
+		SchemaVersion: JSONSchemaVersion,
+		Tool: JSONTool{
+			Name:    "go-coverage-report",
+			Version: data.Metadata.Version,
+		},
+		Inputs: JSONInputs{
+			OldProfile:   data.Metadata.OldProfile,
+			NewProfile:   data.Metadata.NewProfile,
+			ChangedFiles: data.Metadata.ChangedFilesFile,
+			Root:         data.Metadata.Root,
+			Trim:         data.Metadata.Trim,
+			Full:         r.Full,
+		},
+		Summary: JSONSummary{
+			Trend:       data.Trend,
+			NumIncrease: data.NumIncrease,
+			NumDecrease: data.NumDecrease,
+			Old:         jsonValues(data.Total.Old),
+			New:         jsonValues(data.Total.New),
+			Delta:       round(data.Total.Delta, 2),
+			Status:      data.Total.Status,
+			Labels:      jsonLabels(data.Total.Labels),
+		},
+		Packages:  make([]JSONCoverage, 0, len(data.Packages)),
+		Files:     make([]JSONCoverage, 0, len(data.CodeFiles)),
+		TestFiles: make([]string, 0, len(data.TestFiles)),
+		Labels:    data.Labels,
+		Platforms: data.Platforms,
+	}
+
+	if m := data.Metadata; m.BaselineCommit != "" {
+		doc.Inputs.Baseline = &JSONBaseline{
+			Commit:    m.BaselineCommit,
+			MergeBase: m.MergeBase,
+			Distance:  m.BaselineDistance,
+		}
+	}
+
+	for _, pkg := range data.Packages {
+		p := jsonCoverage(pkg)
+		p.Labels = jsonLabels(pkg.Labels)
+		p.Owners = pkg.Owners
+		doc.Packages = append(doc.Packages, p)
+	}
+
+	for _, pkg := range data.IndirectPackages {
+		doc.IndirectPackages = append(doc.IndirectPackages, jsonCoverage(pkg))
+	}
+
+	for _, dir := range data.Directories {
+		d := JSONDirectory{JSONCoverage: jsonCoverage(dir.CoverageDelta)}
+		d.Labels = jsonLabels(dir.Labels)
+		for _, pkg := range dir.Packages {
+			d.Packages = append(d.Packages, pkg.Name)
+		}
+		for _, n := range dir.Tree {
+			if n.Packages > 0 {
+				d.Subdirectories = append(d.Subdirectories, jsonCoverage(n.CoverageDelta))
+			}
+		}
+		doc.Directories = append(doc.Directories, d)
+	}
+
+	for _, o := range data.Owners {
+		owner := JSONOwner{
+			Owner:  o.Owner,
+			Old:    jsonValues(o.Total.Old),
+			New:    jsonValues(o.Total.New),
+			Delta:  round(o.Total.Delta, 2),
+			Status: o.Total.Status,
+		}
+		for _, f := range o.Files {
+			owner.Files = append(owner.Files, f.Name)
+		}
+		doc.Owners = append(doc.Owners, owner)
+	}
+
+	for _, c := range data.Components {
+		doc.Components = append(doc.Components, jsonCoverage(c))
+	}
+
+	for _, pkg := range data.TopGains {
+		doc.TopGains = append(doc.TopGains, jsonCoverage(pkg))
+	}
+
+	for _, pkg := range data.TopLosses {
+		doc.TopLosses = append(doc.TopLosses, jsonCoverage(pkg))
+	}
+
+	for _, f := range data.CodeFiles {
+		file := jsonCoverage(f)
+		file.Package = path.Dir(f.Name)
+		file.Platforms = f.Platforms
+		file.Owners = f.Owners
+		doc.Files = append(doc.Files, file)
+	}
+
+	doc.TestFiles = append(doc.TestFiles, data.TestFiles...)
+
+	return doc
+}
+
+// JSON returns the versioned JSON document of the report as string.
+func (r *Report) JSON() string {
+	data, err := json.MarshalIndent(r.JSONReport(), "", "    ")
+	if err != nil {
+		panic(err) // should never happen
+	}
+
+	return string(data)
+}
+
+func jsonCoverage(d CoverageDelta) JSONCoverage {
+	return JSONCoverage{
+		Name:   d.Name,
+		Old:    jsonValues(d.Old),
+		New:    jsonValues(d.New),
+		Delta:  round(d.Delta, 2),
+		Status: d.Status,
+	}
+}
+
+func jsonValues(v CoverageValues) JSONValues {
+	return JSONValues{
+		Percent:           round(v.Percent, 2),
+		TotalStatements:   v.Total,
+		CoveredStatements: v.Covered,
+		MissedStatements:  v.Missed,
+	}
+}
+
+func jsonLabels(labels []LabelCoverage) []JSONLabelCoverage {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	result := make([]JSONLabelCoverage, 0, len(labels))
+	for _, l := range labels {
+		result = append(result, JSONLabelCoverage{
+			Label:  l.Label,
+			Old:    jsonValues(l.Old),
+			New:    jsonValues(l.New),
+			Delta:  round(l.Delta, 2),
+			Status: l.Status,
+		})
+	}
+
+	return result
+}
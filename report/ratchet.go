@@ -0,0 +1,122 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// Ratchet maps package names to their minimum coverage in percent. It is
+// stored in a file that is committed to the repository so that the coverage
+// of each package can only go up without maintaining thresholds by hand.
+type Ratchet map[string]float64
+
+const ratchetHeader = `# Minimum coverage per package in percent. The coverage of a package must not
+# drop below its value. Update it with "go-coverage-report ratchet update".
+`
+
+// ParseRatchetFile reads the ratchet file with the given name.
+func ParseRatchetFile(filename string) (Ratchet, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadRatchet(f)
+}
+
+// ReadRatchet reads a ratchet file from rd. Each line contains a package name
+// and its minimum coverage separated by whitespace. Empty lines and lines
+// starting with "#" are ignored.
+func ReadRatchet(rd io.Reader) (Ratchet, error) {
+	r := Ratchet{}
+	s := bufio.NewScanner(rd)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected package name and coverage but got %q", line, text)
+		}
+
+		percent, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid coverage %q: %w", line, fields[1], err)
+		}
+
+		r[fields[0]] = percent
+	}
+
+	return r, s.Err()
+}
+
+// Write writes the ratchet file to w. Packages are sorted by name and all
+// values are formatted with two decimal places so that the file only changes
+// when the ratchet values change.
+func (r Ratchet) Write(w io.Writer) error {
+	out := new(strings.Builder)
+	out.WriteString(ratchetHeader)
+	for _, pkg := range sortedKeys(r) {
+		fmt.Fprintf(out, "%s %.2f\n", pkg, r[pkg])
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// Check returns a human-readable description of each package of cov whose
+// coverage is below its ratchet value and of each package of the ratchet that
+// is missing from cov, e.g. because its tests stopped running. Packages
+// without ratchet value are ignored. An empty result means that the check
+// passed.
+func (r Ratchet) Check(cov *coverage.Coverage) []string {
+	pkgs := cov.ByPackage()
+
+	var violations []string
+	for _, pkg := range sortedKeys(r) {
+		minimum := r[pkg]
+		if _, ok := pkgs[pkg]; !ok {
+			violations = append(violations, fmt.Sprintf(
+				"package %s has a ratchet of %.2f%% but is missing from the coverage profile (remove it from the ratchet file if it was deleted)", pkg, minimum,
+			))
+			continue
+		}
+
+		if percent := round(pkgs[pkg].Percent(), 2); percent < minimum {
+			violations = append(violations, fmt.Sprintf(
+				"coverage of package %s is %.2f%% which is below its ratchet of %.2f%%", pkg, percent, minimum,
+			))
+		}
+	}
+
+	return violations
+}
+
+// Update raises the ratchet value of each package whose coverage in cov
+// improved and adds all packages of cov without ratchet value. Values are
+// never lowered. It returns the sorted names of all changed packages.
+func (r Ratchet) Update(cov *coverage.Coverage) []string {
+	pkgs := cov.ByPackage()
+
+	var changed []string
+	for _, pkg := range sortedKeys(pkgs) {
+		percent := round(pkgs[pkg].Percent(), 2)
+		if minimum, ok := r[pkg]; ok && percent <= minimum {
+			continue
+		}
+
+		r[pkg] = percent
+		changed = append(changed, pkg)
+	}
+
+	return changed
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRatchet(t *testing.T) {
+	r, err := ReadRatchet(strings.NewReader(`
+# comment
+example.com/b   50
+example.com/a 90.2
+`))
+	require.NoError(t, err)
+	assert.Equal(t, Ratchet{"example.com/a": 90.2, "example.com/b": 50}, r)
+
+	_, err = ReadRatchet(strings.NewReader("example.com/a\n"))
+	assert.EqualError(t, err, `line 1: expected package name and coverage but got "example.com/a"`)
+}
+
+func TestRatchet_Write(t *testing.T) {
+	r := Ratchet{"example.com/b": 50, "example.com/a": 90.199}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, r.Write(buf))
+
+	expected := ratchetHeader +
+		"example.com/a 90.20\n" +
+		"example.com/b 50.00\n"
+	assert.Equal(t, expected, buf.String())
+
+	parsed, err := ReadRatchet(buf)
+	require.NoError(t, err)
+	assert.Equal(t, Ratchet{"example.com/a": 90.2, "example.com/b": 50}, parsed)
+}
+
+func TestRatchet_CheckAndUpdate(t *testing.T) {
+	cov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/a/a.go:1.1,2.2 1 1
+example.com/a/a.go:3.1,4.2 1 0
+example.com/b/b.go:1.1,2.2 1 1
+example.com/c/c.go:1.1,2.2 1 0
+`), nil)
+	require.NoError(t, err)
+
+	r := Ratchet{
+		"example.com/a":       60,
+		"example.com/b":       80,
+		"example.com/deleted": 100,
+	}
+
+	assert.Equal(t, []string{
+		"coverage of package example.com/a is 50.00% which is below its ratchet of 60.00%",
+		"package example.com/deleted has a ratchet of 100.00% but is missing from the coverage profile (remove it from the ratchet file if it was deleted)",
+	}, r.Check(cov))
+
+	changed := r.Update(cov)
+	assert.Equal(t, []string{"example.com/b", "example.com/c"}, changed)
+	assert.Equal(t, Ratchet{
+		"example.com/a":       60,
+		"example.com/b":       100,
+		"example.com/c":       0,
+		"example.com/deleted": 100,
+	}, r)
+}
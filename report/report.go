@@ -0,0 +1,428 @@
+// Package report compares two coverage profiles and renders the coverage
+// changes of a list of changed files as Markdown, JSON or custom template.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// Report compares the Old and New coverage of the ChangedFiles.
+type Report struct {
+	Old, New        *coverage.Coverage
+	ChangedFiles    []string
+	ChangedPackages []string
+	Labels          []Label
+	Metadata        Metadata
+
+	// IndirectThreshold enables the section of indirectly impacted packages
+	// if it is positive. It lists all packages without changed files whose
+	// coverage changed by more than IndirectThreshold percentage points
+	// (e.g. because tests or test helpers of another package changed).
+	IndirectThreshold float64
+
+	// Full is true if the report compares all files of both profiles instead
+	// of a list of changed files (see NewFull). Packages and files are then
+	// sorted by their coverage delta unless another Sort order is set.
+	Full bool
+	// TopMovers is the number of packages with the largest coverage gains
+	// and losses that are listed in separate sections. Zero disables them.
+	TopMovers int
+
+	// History contains the coverage of previous commits, oldest first. If
+	// it is not empty, the report shows the coverage trend of each package.
+	History []HistoryEntry
+
+	// RollupDepth groups the impacted packages by their directory of at most
+	// RollupDepth path segments if it is positive. Each directory shows the
+	// combined coverage of all packages in it and its subdirectories.
+	RollupDepth int
+
+	// CodeOwners attributes the changed files and packages to their owners
+	// if it is set. The report then shows the coverage of each owner.
+	CodeOwners *CodeOwners
+
+	// Components are named groups of files whose combined coverage is shown
+	// in a separate table, independent of the package structure.
+	Components []Component
+
+	// Scoring is the policy that scores the coverage changes of the packages,
+	// files and the total coverage. If it is nil, the DefaultScoringPolicy is
+	// used. Otherwise, the title is also followed by the score of the total
+	// coverage.
+	Scoring ScoringPolicy
+
+	// Accessible renders coverage changes with arrows and words instead of
+	// emojis and Markdown emphasis (e.g. "▼ 12.30 pp" scored as "regression")
+	// for screen readers and plain text notifications. The headline is then
+	// rendered without emphasis and an EmojiPolicy is replaced by the
+	// AccessiblePolicy.
+	Accessible bool
+
+	// Sort is the order of the packages and files. If it is empty, they are
+	// sorted by name (or by delta if the report is Full).
+	Sort SortOrder
+	// HideUnchanged omits packages and files whose coverage did not change.
+	HideUnchanged bool
+	// MinStatements omits packages and files with fewer statements in both
+	// the old and the new profile if it is positive.
+	MinStatements int64
+
+	// MaxLength is the maximum number of characters of the rendered report
+	// if it is positive. Longer reports are truncated by progressively
+	// omitting details (see Template).
+	MaxLength int
+}
+
+// Label is a named part of the Old and New coverage (e.g. the coverage of
+// only the unit or integration tests) that is shown in addition to the
+// combined coverage of the report.
+type Label struct {
+	Name     string
+	Old, New *coverage.Coverage
+}
+
+// New creates a Report of the given changed files. The changedFiles slice is
+// sorted in place.
+func New(oldCov, newCov *coverage.Coverage, changedFiles []string) *Report {
+	slices.Sort(changedFiles)
+	return &Report{
+		Old:             oldCov,
+		New:             newCov,
+		ChangedFiles:    changedFiles,
+		ChangedPackages: changedPackages(changedFiles),
+	}
+}
+
+// NewFull creates a Report that compares all files in the union of both
+// profiles, e.g. for nightly comparisons between releases for which there
+// is no list of changed files.
+func NewFull(oldCov, newCov *coverage.Coverage) *Report {
+	files := map[string]bool{}
+	for name := range oldCov.Files {
+		files[name] = true
+	}
+	for name := range newCov.Files {
+		files[name] = true
+	}
+
+	r := New(oldCov, newCov, sortedKeys(files))
+	r.Full = true
+	return r
+}
+
+// AddLabel adds the coverage of a labeled subset of the tests to the report.
+// A nil coverage is treated as empty coverage (e.g. if a label only exists
+// in the new profiles).
+func (r *Report) AddLabel(name string, oldCov, newCov *coverage.Coverage) {
+	if oldCov == nil {
+		oldCov = coverage.New(nil)
+	}
+	if newCov == nil {
+		newCov = coverage.New(nil)
+	}
+
+	r.Labels = append(r.Labels, Label{Name: name, Old: oldCov, New: newCov})
+}
+
+func changedPackages(changedFiles []string) []string {
+	packages := map[string]bool{}
+	for _, file := range changedFiles {
+		pkg := filepath.Dir(file)
+		packages[pkg] = true
+	}
+
+	result := make([]string, 0, len(packages))
+	for pkg := range packages {
+		result = append(result, pkg)
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// IndirectPackages returns the sorted names of all packages that are not
+// in ChangedPackages but whose coverage changed by more than the
+// IndirectThreshold. It returns nil if the threshold is not positive.
+func (r *Report) IndirectPackages() []string {
+	if r.IndirectThreshold <= 0 {
+		return nil
+	}
+
+	oldCovPkgs := r.Old.ByPackage()
+	newCovPkgs := r.New.ByPackage()
+
+	all := map[string]bool{}
+	for pkg := range oldCovPkgs {
+		all[pkg] = true
+	}
+	for pkg := range newCovPkgs {
+		all[pkg] = true
+	}
+
+	var result []string
+	for _, pkg := range sortedKeys(all) {
+		if slices.Contains(r.ChangedPackages, pkg) {
+			continue
+		}
+
+		oldP := round(coverageValues(oldCovPkgs[pkg]).Percent, 2)
+		newP := round(coverageValues(newCovPkgs[pkg]).Percent, 2)
+		if math.Abs(newP-oldP) > r.IndirectThreshold {
+			result = append(result, pkg)
+		}
+	}
+
+	return result
+}
+
+// Title returns the Markdown heading of the report.
+func (r *Report) Title() string {
+	return fmt.Sprintf("### %s\n", r.headline())
+}
+
+func (r *Report) headline() string {
+	title := r.trendHeadline()
+	if r.Accessible {
+		title = strings.ReplaceAll(title, "**", "")
+	}
+
+	if r.Scoring != nil {
+		if score := r.scoring().Score(r.New.Percent(), r.Old.Percent()); score != "" {
+			title += " " + score
+		}
+	}
+
+	return title
+}
+
+// scoring returns the scoring policy of the report. Accessible reports
+// never score with emojis, so they use the AccessiblePolicy unless another
+// policy than the EmojiPolicy is configured.
+func (r *Report) scoring() ScoringPolicy {
+	_, emoji := r.Scoring.(EmojiPolicy)
+	switch {
+	case r.Accessible && (r.Scoring == nil || emoji):
+		return AccessiblePolicy
+	case r.Scoring == nil:
+		return DefaultScoringPolicy
+	}
+
+	return r.Scoring
+}
+
+func (r *Report) trendHeadline() string {
+	numIncrease, numDecrease := r.countChanges()
+	if r.Full {
+		return fullHeadline(numIncrease, numDecrease)
+	}
+
+	switch trend(numIncrease, numDecrease) {
+	case "no change":
+		return "Merging this branch will **not change** overall coverage"
+	case "increase":
+		return "Merging this branch will **increase** overall coverage"
+	case "decrease":
+		return "Merging this branch will **decrease** overall coverage"
+	default:
+		return fmt.Sprintf("Merging this branch changes the coverage (%d decrease, %d increase)", numDecrease, numIncrease)
+	}
+}
+
+func fullHeadline(numIncrease, numDecrease int) string {
+	switch trend(numIncrease, numDecrease) {
+	case "no change":
+		return "The coverage of all packages is **unchanged**"
+	case "increase":
+		return "The coverage **increased** in " + numPackages(numIncrease)
+	case "decrease":
+		return "The coverage **decreased** in " + numPackages(numDecrease)
+	default:
+		return fmt.Sprintf("The coverage changed in %s (%d decrease, %d increase)", numPackages(numIncrease+numDecrease), numDecrease, numIncrease)
+	}
+}
+
+func numPackages(n int) string {
+	if n == 1 {
+		return "1 package"
+	}
+
+	return fmt.Sprintf("%d packages", n)
+}
+
+// countChanges returns the number of changed packages whose coverage
+// increased and decreased respectively.
+func (r *Report) countChanges() (numIncrease, numDecrease int) {
+	oldCovPkgs := r.Old.ByPackage()
+	newCovPkgs := r.New.ByPackage()
+
+	for _, pkg := range r.ChangedPackages {
+		var oldPercent, newPercent float64
+
+		if cov, ok := oldCovPkgs[pkg]; ok {
+			oldPercent = cov.Percent()
+		}
+
+		if cov, ok := newCovPkgs[pkg]; ok {
+			newPercent = cov.Percent()
+		}
+
+		newP := round(newPercent, 2) // do rounding here so the diff is not affected by confusing rounding errors in the third decimal place
+		oldP := round(oldPercent, 2)
+		switch {
+		case newP > oldP:
+			numIncrease++
+		case newP < oldP:
+			numDecrease++
+		}
+	}
+
+	return numIncrease, numDecrease
+}
+
+func trend(numIncrease, numDecrease int) string {
+	switch {
+	case numIncrease == 0 && numDecrease == 0:
+		return "no change"
+	case numIncrease > 0 && numDecrease == 0:
+		return "increase"
+	case numIncrease == 0 && numDecrease > 0:
+		return "decrease"
+	default:
+		return "mixed"
+	}
+}
+
+// Markdown renders the report using the built-in Markdown template.
+func (r *Report) Markdown() string {
+	report, err := r.Template(mustParseDefaultTemplate())
+	if err != nil {
+		panic(err) // should never happen since the default template is covered by unit tests
+	}
+
+	return report
+}
+
+// TrimPrefix removes the given prefix from all package and file names.
+func (r *Report) TrimPrefix(prefix string) {
+	for i, name := range r.ChangedPackages {
+		r.ChangedPackages[i] = coverage.TrimPathPrefix(name, prefix)
+	}
+	for i, name := range r.ChangedFiles {
+		r.ChangedFiles[i] = coverage.TrimPathPrefix(name, prefix)
+	}
+
+	r.Old.TrimPrefix(prefix)
+	r.New.TrimPrefix(prefix)
+	r.trimHistoryPrefix(prefix)
+	if r.CodeOwners != nil && r.CodeOwners.Root != "" {
+		r.CodeOwners.Root = coverage.TrimPathPrefix(r.CodeOwners.Root, prefix)
+		if r.CodeOwners.Root == "." {
+			r.CodeOwners.Root = ""
+		}
+	}
+	for _, l := range r.Labels {
+		l.Old.TrimPrefix(prefix)
+		l.New.TrimPrefix(prefix)
+	}
+}
+
+// WriteMetrics writes the key=value coverage metrics that are used as
+// GitHub Actions outputs to w.
+func (r *Report) WriteMetrics(w io.Writer) error {
+	newPct := round(r.New.Percent(), 2)
+	oldPct := round(r.Old.Percent(), 2)
+	delta := round(newPct-oldPct, 2)
+
+	var trend string
+	switch {
+	case delta > 0:
+		trend = "increased"
+	case delta < 0:
+		trend = "decreased"
+	default:
+		trend = "no change"
+	}
+
+	content := strings.Join([]string{
+		fmt.Sprintf("total_coverage=%.2f", newPct),
+		fmt.Sprintf("coverage_delta=%.2f", delta),
+		fmt.Sprintf("coverage_trend=%s", trend),
+		fmt.Sprintf("total_statements=%d", r.New.TotalStmt),
+		fmt.Sprintf("covered_statements=%d", r.New.CoveredStmt),
+		fmt.Sprintf("missed_statements=%d", r.New.MissedStmt),
+	}, "\n") + "\n"
+
+	if r.CodeOwners != nil {
+		// The owners are computed from all changed files since hidden rows
+		// must not change the metrics.
+		var files, owners []CoverageDelta
+		for _, f := range r.ChangedFiles {
+			if !strings.HasSuffix(f, "_test.go") {
+				files = append(files, CoverageDelta{Name: f, Owners: r.CodeOwners.Owners(f)})
+			}
+		}
+		for _, o := range r.owners(files) {
+			owners = append(owners, o.Total)
+		}
+
+		metrics, err := groupMetrics("owner", owners)
+		if err != nil {
+			return err
+		}
+		content += metrics
+	}
+
+	if len(r.Components) > 0 {
+		metrics, err := groupMetrics("component", r.components())
+		if err != nil {
+			return err
+		}
+		content += metrics
+	}
+
+	_, err := io.WriteString(w, content)
+	return err
+}
+
+// groupMetrics returns the coverage and delta of the named groups (e.g. code
+// owners) as metrics. Their values are JSON objects that map the group names
+// to their values so they can be used via fromJSON in GitHub Actions
+// regardless of the group names.
+func groupMetrics(prefix string, groups []CoverageDelta) (string, error) {
+	percents, deltas := map[string]float64{}, map[string]float64{}
+	for _, g := range groups {
+		percents[g.Name] = round(g.New.Percent, 2)
+		deltas[g.Name] = round(g.Delta, 2)
+	}
+
+	coverageJSON, err := json.Marshal(percents)
+	if err != nil {
+		return "", err
+	}
+
+	deltaJSON, err := json.Marshal(deltas)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s_coverage=%s\n%s_coverage_delta=%s\n", prefix, coverageJSON, prefix, deltaJSON), nil
+}
+
+func round(val float64, places int) float64 {
+	if val == 0 {
+		return 0
+	}
+
+	pow := math.Pow10(places)
+	digit := math.Round(pow * val)
+	return digit / pow
+}
@@ -0,0 +1,432 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Markdown(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/01-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, changedFiles)
+	actual := report.Markdown()
+
+	expected := `### Merging this branch will **decrease** overall coverage
+
+| Impacted Packages | Coverage Δ | :robot: |
+|-------------------|------------|---------|
+| github.com/fgrosse/prioqueue | 90.20% (**-9.80%**) | :thumbsdown: |
+| github.com/fgrosse/prioqueue/foo/bar | 0.00% (ø) |  |
+
+---
+
+<details>
+
+<summary>Coverage by file</summary>
+
+### Changed files (no unit tests)
+
+| Changed File | Coverage Δ | Total | Covered | Missed | :robot: |
+|--------------|------------|-------|---------|--------|---------|
+| github.com/fgrosse/prioqueue/foo/bar/baz.go | 0.00% (ø) | 0 | 0 | 0 |  |
+| github.com/fgrosse/prioqueue/min_heap.go | 80.77% (**-19.23%**) | 52 (+2) | 42 (-8) | 10 (+10) | :skull:  |
+
+_Please note that the "Total", "Covered", and "Missed" counts above refer to ***code statements*** instead of lines of code. The value in brackets refers to the test coverage of that file in the old version of the code._
+
+</details>`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteMetrics(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/01-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, changedFiles)
+	content := new(bytes.Buffer)
+
+	err = report.WriteMetrics(content)
+	require.NoError(t, err)
+
+	expected := "total_coverage=90.20\ncoverage_delta=-9.80\ncoverage_trend=decreased\ntotal_statements=102\ncovered_statements=92\nmissed_statements=10\n"
+	assert.Equal(t, expected, content.String())
+}
+
+func TestReport_Markdown_OnlyChangedUnitTests(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/02-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/02-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/02-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, changedFiles)
+	actual := report.Markdown()
+
+	expected := `### Merging this branch will **increase** overall coverage
+
+| Impacted Packages | Coverage Δ | :robot: |
+|-------------------|------------|---------|
+| github.com/fgrosse/prioqueue | 99.02% (**+8.82%**) | :thumbsup: |
+
+---
+
+<details>
+
+<summary>Coverage by file</summary>
+
+### Changed unit test files
+
+- github.com/fgrosse/prioqueue/min_heap_test.go
+
+</details>`
+	assert.Equal(t, expected, actual)
+}
+
+func TestReport_JSON(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/01-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, changedFiles)
+	report.Metadata = Metadata{
+		Version:          "dev",
+		OldProfile:       "testdata/01-old-coverage.txt",
+		NewProfile:       "testdata/01-new-coverage.txt",
+		ChangedFilesFile: "testdata/01-changed-files.json",
+		Root:             "github.com/fgrosse/prioqueue",
+	}
+
+	expected, err := os.ReadFile("testdata/01-report.json")
+	require.NoError(t, err)
+
+	assert.JSONEq(t, string(expected), report.JSON())
+}
+
+func TestReport_Markdown_Labels(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	e2eCov, err := coverage.ParseFile("testdata/02-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/02-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, changedFiles)
+	report.AddLabel("unit", oldCov, newCov)
+	report.AddLabel("e2e", nil, e2eCov)
+	report.TrimPrefix("github.com/fgrosse/")
+
+	expected := `### Merging this branch will **decrease** overall coverage
+
+| Impacted Packages | unit | e2e | Coverage Δ | :robot: |
+|-------------------|------------|------------|------------|---------|
+| prioqueue | 90.20% (**-9.80%**) | 90.20% (**+90.20%**) | 90.20% (**-9.80%**) | :thumbsdown: |
+
+---
+
+<details>
+
+<summary>Coverage by file</summary>
+
+### Changed unit test files
+
+- prioqueue/min_heap_test.go
+
+</details>`
+	assert.Equal(t, expected, report.Markdown())
+
+	doc := report.JSONReport()
+	assert.Equal(t, []string{"unit", "e2e"}, doc.Labels)
+	require.Len(t, doc.Packages, 1)
+	require.Len(t, doc.Packages[0].Labels, 2)
+	assert.Equal(t, "e2e", doc.Packages[0].Labels[1].Label)
+	assert.InDelta(t, 90.2, doc.Packages[0].Labels[1].Delta, 0.001)
+	assert.Equal(t, "increased", doc.Packages[0].Labels[1].Status)
+}
+
+func TestReport_Markdown_Platforms(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	linux, err := coverage.ParseProfiles("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+	for _, p := range linux {
+		p.Platforms = []string{"linux"}
+	}
+
+	windows := []*coverage.Profile{{
+		FileName:  "github.com/fgrosse/prioqueue/foo/bar/baz_windows.go",
+		Mode:      "count",
+		Platforms: []string{"windows"},
+	}}
+
+	changedFiles := []string{
+		"github.com/fgrosse/prioqueue/foo/bar/baz_windows.go",
+		"github.com/fgrosse/prioqueue/min_heap.go",
+	}
+
+	report := New(oldCov, coverage.New(append(linux, windows...)), changedFiles)
+	report.TrimPrefix("github.com/fgrosse/")
+
+	expected := "| Changed File | Coverage Δ | Total | Covered | Missed | Platforms | :robot: |\n" +
+		"|--------------|------------|-------|---------|--------|-----------|---------|\n" +
+		"| prioqueue/foo/bar/baz_windows.go | 0.00% (ø) | 0 | 0 | 0 | windows |  |\n" +
+		"| prioqueue/min_heap.go | 80.77% (**-19.23%**) | 52 (+2) | 42 (-8) | 10 (+10) | linux | :skull:  |\n"
+	assert.Contains(t, report.Markdown(), expected)
+	assert.Equal(t, []string{"linux", "windows"}, report.JSONReport().Platforms)
+}
+
+func TestReport_Markdown_IndirectPackages(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, []string{"github.com/fgrosse/other/other_test.go"})
+	report.TrimPrefix("github.com/fgrosse/")
+	assert.NotContains(t, report.Markdown(), "Indirectly impacted packages")
+
+	report.IndirectThreshold = 10
+	assert.Empty(t, report.IndirectPackages())
+
+	report.IndirectThreshold = 5
+	assert.Equal(t, []string{"prioqueue"}, report.IndirectPackages())
+
+	expected := "| other | 0.00% (ø) |  |\n" +
+		"\n" +
+		"### Indirectly impacted packages\n" +
+		"\n" +
+		"The coverage of these packages changed although none of their files were changed.\n" +
+		"\n" +
+		"| Package | Coverage Δ | :robot: |\n" +
+		"|---------|------------|---------|\n" +
+		"| prioqueue | 90.20% (**-9.80%**) | :thumbsdown: |\n" +
+		"\n" +
+		"---\n"
+	assert.Contains(t, report.Markdown(), expected)
+
+	doc := report.JSONReport()
+	require.Len(t, doc.IndirectPackages, 1)
+	assert.Equal(t, -9.8, doc.IndirectPackages[0].Delta)
+}
+
+func TestReport_Markdown_Full(t *testing.T) {
+	oldCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/a/a.go:1.1,2.2 1 0
+example.com/a/a.go:3.1,4.2 1 1
+example.com/b/b.go:1.1,2.2 1 1
+example.com/c/c.go:1.1,2.2 1 1
+example.com/d/d.go:1.1,2.2 4 1
+`), nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/a/a.go:1.1,2.2 1 1
+example.com/a/a.go:3.1,4.2 1 1
+example.com/b/b.go:1.1,2.2 1 0
+example.com/c/c.go:1.1,2.2 1 1
+example.com/d/d.go:1.1,2.2 4 1
+example.com/d/d.go:3.1,4.2 1 0
+`), nil)
+	require.NoError(t, err)
+
+	report := NewFull(oldCov, newCov)
+	report.TopMovers = 1
+	report.TrimPrefix("example.com/")
+
+	expected := `### The coverage changed in 3 packages (2 decrease, 1 increase)
+
+### Top gains
+
+| Package | Coverage Δ | :robot: |
+|---------|------------|---------|
+| a | 100.00% (**+50.00%**) | :star2: |
+
+### Top losses
+
+| Package | Coverage Δ | :robot: |
+|---------|------------|---------|
+| b | 0.00% (**-100.00%**) | :skull: :skull: :skull: :skull: :skull:  |
+
+| Impacted Packages | Coverage Δ | :robot: |
+|-------------------|------------|---------|
+| b | 0.00% (**-100.00%**) | :skull: :skull: :skull: :skull: :skull:  |
+| d | 80.00% (**-20.00%**) | :skull: :skull:  |
+| c | 100.00% (ø) |  |
+| a | 100.00% (**+50.00%**) | :star2: |
+`
+	assert.True(t, strings.HasPrefix(report.Markdown(), expected), report.Markdown())
+
+	doc := report.JSONReport()
+	assert.True(t, doc.Inputs.Full)
+	assert.Equal(t, []string{"b/b.go", "d/d.go", "c/c.go", "a/a.go"}, []string{doc.Files[0].Name, doc.Files[1].Name, doc.Files[2].Name, doc.Files[3].Name})
+	require.Len(t, doc.TopGains, 1)
+	require.Len(t, doc.TopLosses, 1)
+	assert.Equal(t, "d", doc.Packages[1].Name)
+}
+
+func TestReport_Markdown_Baseline(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, []string{"github.com/fgrosse/prioqueue/min_heap.go"})
+	report.Metadata.BaselineCommit = "1111111aaaaaaa"
+	report.Metadata.MergeBase = "1111111aaaaaaa"
+
+	expected := "### Merging this branch will **decrease** overall coverage\n" +
+		"\n" +
+		"_Compared against the coverage of the merge base 1111111._\n" +
+		"\n" +
+		"| Impacted Packages |"
+	assert.True(t, strings.HasPrefix(report.Markdown(), expected), report.Markdown())
+
+	report.Metadata.MergeBase = "2222222bbbbbbb"
+	report.Metadata.BaselineDistance = 3
+
+	expected = "### Merging this branch will **decrease** overall coverage\n" +
+		"\n" +
+		"> [!WARNING]\n" +
+		"> The baseline coverage is from commit 1111111 which is 3 commits older than the merge base 2222222. " +
+		"Coverage changes of these commits are included in the deltas below.\n" +
+		"\n" +
+		"| Impacted Packages |"
+	assert.True(t, strings.HasPrefix(report.Markdown(), expected), report.Markdown())
+	assert.Equal(t, &JSONBaseline{Commit: "1111111aaaaaaa", MergeBase: "2222222bbbbbbb", Distance: 3}, report.JSONReport().Inputs.Baseline)
+}
+
+func TestReport_Markdown_Rollup(t *testing.T) {
+	oldCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/internal/storage/sql/sql.go:1.1,2.2 1 1
+example.com/internal/storage/sql/sql.go:3.1,4.2 1 0
+example.com/internal/storage/kv/kv.go:1.1,2.2 2 1
+example.com/internal/storage/kv/mem/mem.go:1.1,2.2 1 1
+example.com/cmd/cmd.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/internal/storage/sql/sql.go:1.1,2.2 1 1
+example.com/internal/storage/sql/sql.go:3.1,4.2 1 1
+example.com/internal/storage/kv/kv.go:1.1,2.2 2 1
+example.com/internal/storage/kv/mem/mem.go:1.1,2.2 1 0
+example.com/cmd/cmd.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, []string{
+		"example.com/cmd/cmd.go",
+		"example.com/internal/storage/kv/mem/mem.go",
+		"example.com/internal/storage/sql/sql.go",
+	})
+	report.TrimPrefix("example.com/")
+	report.RollupDepth = 2
+
+	expected := "| Impacted Packages | Coverage Δ | :robot: |\n" +
+		"|-------------------|------------|---------|\n" +
+		"| cmd | 100.00% (ø) |  |\n" +
+		"| internal/storage/… (2 packages) | 80.00% (ø) |  |\n" +
+		"\n" +
+		"<details>\n" +
+		"\n" +
+		"<summary>internal/storage</summary>\n" +
+		"\n" +
+		"| Package | Coverage Δ | :robot: |\n" +
+		"|---------|------------|---------|\n" +
+		"| internal/storage/kv/mem | 0.00% (**-100.00%**) | :skull: :skull: :skull: :skull: :skull:  |\n" +
+		"| internal/storage/sql | 100.00% (**+50.00%**) | :star2: |\n" +
+		"\n" +
+		"</details>\n" +
+		"\n" +
+		"---\n"
+	assert.Contains(t, report.Markdown(), expected)
+
+	doc := report.JSONReport()
+	require.Len(t, doc.Directories, 2)
+	assert.Equal(t, "internal/storage", doc.Directories[1].Name)
+	assert.Equal(t, int64(5), doc.Directories[1].New.TotalStatements)
+	assert.Equal(t, []string{"internal/storage/kv/mem", "internal/storage/sql"}, doc.Directories[1].Packages)
+}
+
+func TestReport_Markdown_RollupTree(t *testing.T) {
+	oldCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/internal/storage/sql/sql.go:1.1,2.2 1 1
+example.com/internal/storage/sql/sql.go:3.1,4.2 1 0
+example.com/internal/storage/kv/kv.go:1.1,2.2 2 1
+example.com/internal/storage/kv/mem/mem.go:1.1,2.2 1 1
+example.com/cmd/cmd.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/internal/storage/sql/sql.go:1.1,2.2 1 1
+example.com/internal/storage/sql/sql.go:3.1,4.2 1 1
+example.com/internal/storage/kv/kv.go:1.1,2.2 2 0
+example.com/internal/storage/kv/mem/mem.go:1.1,2.2 1 0
+example.com/cmd/cmd.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, []string{
+		"example.com/cmd/cmd.go",
+		"example.com/internal/storage/kv/kv.go",
+		"example.com/internal/storage/kv/mem/mem.go",
+		"example.com/internal/storage/sql/sql.go",
+	})
+	report.TrimPrefix("example.com/")
+	report.RollupDepth = 1
+
+	expected := "<summary>internal</summary>\n" +
+		"\n" +
+		"| Package | Coverage Δ | :robot: |\n" +
+		"|---------|------------|---------|\n" +
+		"| internal/storage/… (3 packages) | 40.00% (**-40.00%**) | :skull: :skull: :skull: :skull:  |\n" +
+		"| &nbsp;&nbsp;internal/storage/kv/… (2 packages) | 0.00% (**-100.00%**) | :skull: :skull: :skull: :skull: :skull:  |\n" +
+		"| &nbsp;&nbsp;&nbsp;&nbsp;internal/storage/kv | 0.00% (**-100.00%**) | :skull: :skull: :skull: :skull: :skull:  |\n" +
+		"| &nbsp;&nbsp;&nbsp;&nbsp;internal/storage/kv/mem | 0.00% (**-100.00%**) | :skull: :skull: :skull: :skull: :skull:  |\n" +
+		"| &nbsp;&nbsp;internal/storage/sql | 100.00% (**+50.00%**) | :star2: |\n" +
+		"\n" +
+		"</details>\n"
+	assert.Contains(t, report.Markdown(), expected)
+
+	doc := report.JSONReport()
+	require.Len(t, doc.Directories, 2)
+	assert.Equal(t, "internal", doc.Directories[1].Name)
+	require.Len(t, doc.Directories[1].Subdirectories, 2)
+	assert.Equal(t, "internal/storage", doc.Directories[1].Subdirectories[0].Name)
+	assert.Equal(t, "internal/storage/kv", doc.Directories[1].Subdirectories[1].Name)
+	assert.Equal(t, int64(3), doc.Directories[1].Subdirectories[1].New.TotalStatements)
+}
@@ -0,0 +1,183 @@
+package report
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// DirectoryDelta is the combined coverage of all packages in a directory
+// and its subdirectories if the report rolls up packages (see RollupDepth).
+type DirectoryDelta struct {
+	CoverageDelta
+	// Packages contains the changed packages within the directory sorted
+	// like the Packages of the TemplateData.
+	Packages []CoverageDelta
+	// Rollup is false if the directory consists only of a single changed
+	// package with the same name.
+	Rollup bool
+	// Tree contains the Packages together with a row for each subdirectory
+	// that contains more than one of them, in depth-first order.
+	Tree []DirectoryNode
+}
+
+// DirectoryNode is a row in the tree of the changed packages of a directory.
+// It is either a changed package or a subdirectory with the combined coverage
+// of all packages in it and its subdirectories.
+type DirectoryNode struct {
+	CoverageDelta
+	// Depth is the number of subdirectory rows above the row in the tree.
+	Depth int
+	// Packages is the number of changed packages in a subdirectory. It is
+	// zero for package rows.
+	Packages int
+}
+
+// rollupDir returns the first depth path segments of the package name.
+func rollupDir(pkg string, depth int) string {
+	segments := strings.Split(pkg, "/")
+	if len(segments) <= depth {
+		return pkg
+	}
+
+	return strings.Join(segments[:depth], "/")
+}
+
+// directories groups the changed packages by their directory of at most
+// RollupDepth path segments. It returns nil if the depth is not positive.
+func (r *Report) directories(packages []CoverageDelta) []DirectoryDelta {
+	if r.RollupDepth <= 0 {
+		return nil
+	}
+
+	var result []DirectoryDelta
+	index := map[string]int{}
+	for _, pkg := range packages {
+		dir := rollupDir(pkg.Name, r.RollupDepth)
+		i, ok := index[dir]
+		if !ok {
+			i = len(result)
+			index[dir] = i
+			result = append(result, DirectoryDelta{CoverageDelta: r.directoryDelta(dir)})
+		}
+
+		result[i].Packages = append(result[i].Packages, pkg)
+	}
+
+	for i, d := range result {
+		result[i].Rollup = len(d.Packages) > 1 || d.Packages[0].Name != d.Name
+	}
+
+	return result
+}
+
+// directoryTree arranges the changed packages of the directory as a tree.
+// Every subdirectory (or package with subpackages) that contains at least
+// two changed packages gets its own row above them, so each level of the
+// tree shows the combined coverage of its children. The rows of each level
+// keep the order of their first package and a subdirectory comes before the
+// package with the same name.
+func (r *Report) directoryTree(d DirectoryDelta) []DirectoryNode {
+	counts := map[string]int{}
+	first := map[string]int{}
+	for i, pkg := range d.Packages {
+		for dir := pkg.Name; strings.HasPrefix(dir, d.Name+"/"); dir = path.Dir(dir) {
+			if counts[dir] == 0 {
+				first[dir] = i
+			}
+			counts[dir]++
+		}
+	}
+
+	// parent returns the closest subdirectory row above the row with the
+	// given name or the directory itself.
+	parent := func(name string, isDir bool) string {
+		if !isDir && counts[name] > 1 {
+			return name
+		}
+		for dir := path.Dir(name); strings.HasPrefix(dir, d.Name+"/"); dir = path.Dir(dir) {
+			if counts[dir] > 1 {
+				return dir
+			}
+		}
+		return d.Name
+	}
+
+	children := map[string][]DirectoryNode{}
+	for dir, n := range counts {
+		if n > 1 {
+			p := parent(dir, true)
+			children[p] = append(children[p], DirectoryNode{CoverageDelta: r.directoryDelta(dir), Packages: n})
+		}
+	}
+	for _, pkg := range d.Packages {
+		p := parent(pkg.Name, false)
+		children[p] = append(children[p], DirectoryNode{CoverageDelta: pkg})
+	}
+
+	var nodes []DirectoryNode
+	var walk func(name string, depth int)
+	walk = func(name string, depth int) {
+		rows := children[name]
+		slices.SortFunc(rows, func(a, b DirectoryNode) int {
+			if c := cmp.Compare(first[a.Name], first[b.Name]); c != 0 {
+				return c
+			}
+			return cmp.Compare(b.Packages, a.Packages)
+		})
+		for _, n := range rows {
+			n.Depth = depth
+			nodes = append(nodes, n)
+			if n.Packages > 0 {
+				walk(n.Name, depth+1)
+			}
+		}
+	}
+	walk(d.Name, 0)
+
+	return nodes
+}
+
+func (r *Report) directoryDelta(dir string) CoverageDelta {
+	d := r.newCoverageDelta(dir, directoryValues(r.Old, dir), directoryValues(r.New, dir))
+	for _, l := range r.Labels {
+		d.Labels = append(d.Labels, LabelCoverage{
+			Label:         l.Name,
+			CoverageDelta: r.newCoverageDelta(dir, directoryValues(l.Old, dir), directoryValues(l.New, dir)),
+		})
+	}
+
+	return d
+}
+
+// directoryValues sums up the statements of all packages of cov in the
+// given directory and its subdirectories.
+func directoryValues(cov *coverage.Coverage, dir string) CoverageValues {
+	return filesValues(cov, func(file string) bool {
+		pkg := path.Dir(file)
+		return pkg == dir || strings.HasPrefix(pkg, dir+"/")
+	})
+}
+
+// filesValues sums up the statements of all files of cov that match.
+func filesValues(cov *coverage.Coverage, match func(file string) bool) CoverageValues {
+	var v CoverageValues
+	for name, p := range cov.Files {
+		if !match(name) {
+			continue
+		}
+
+		v.Total += p.GetTotal()
+		v.Covered += p.GetCovered()
+		v.Missed += p.GetMissed()
+	}
+
+	if v.Total > 0 {
+		v.Percent = float64(v.Covered) / float64(v.Total) * 100
+	}
+
+	return v
+}
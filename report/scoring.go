@@ -0,0 +1,192 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ScoringPolicy determines the score (e.g. an emoji) that is shown next to
+// the coverage change of a package, file or the total coverage.
+type ScoringPolicy interface {
+	Score(newPercent, oldPercent float64) string
+}
+
+// DefaultScoringPolicy is the scoring policy of reports that do not
+// configure their own.
+var DefaultScoringPolicy ScoringPolicy = EmojiPolicy{
+	Thresholds: Thresholds{Skull: -10, Tada: 10, Star: 20},
+}
+
+// EmojiPolicy scores coverage changes with emojis. A decrease below
+// Thresholds.Skull scores a skull for every full multiple of it (up to five)
+// and any other decrease a thumbs down. An increase above Thresholds.Star
+// scores a star, above Thresholds.Tada a tada and any other increase a
+// thumbs up.
+type EmojiPolicy struct {
+	Thresholds Thresholds
+}
+
+// Score implements the ScoringPolicy interface.
+func (p EmojiPolicy) Score(newPercent, oldPercent float64) string {
+	diff := newPercent - oldPercent
+	switch {
+	case diff < 5*p.Thresholds.Skull:
+		return strings.Repeat(":skull: ", 5)
+	case diff < p.Thresholds.Skull:
+		return strings.Repeat(":skull: ", int(diff/p.Thresholds.Skull))
+	case diff < 0:
+		return ":thumbsdown:"
+	case diff == 0:
+		return ""
+	case diff > p.Thresholds.Star:
+		return ":star2:"
+	case diff > p.Thresholds.Tada:
+		return ":tada:"
+	default:
+		return ":thumbsup:"
+	}
+}
+
+// ThresholdPolicy scores the coverage change (or the new coverage if
+// Coverage is true) with the Symbol of the level with the largest Min that
+// is less than or equal to it. Unchanged coverage is never scored by
+// policies of the coverage change.
+type ThresholdPolicy struct {
+	Coverage bool
+	Levels   []ScoreLevel
+}
+
+// ScoreLevel is the symbol of a ThresholdPolicy for all values of at least Min.
+type ScoreLevel struct {
+	Min    float64
+	Symbol string
+}
+
+// Score implements the ScoringPolicy interface.
+func (p ThresholdPolicy) Score(newPercent, oldPercent float64) string {
+	value := round(newPercent-oldPercent, 2)
+	if p.Coverage {
+		value = round(newPercent, 2)
+	} else if value == 0 {
+		return ""
+	}
+
+	var best *ScoreLevel
+	for i, l := range p.Levels {
+		if value >= l.Min && (best == nil || l.Min > best.Min) {
+			best = &p.Levels[i]
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return best.Symbol
+}
+
+// TextPolicy scores coverage changes without emojis.
+var TextPolicy = ThresholdPolicy{Levels: []ScoreLevel{
+	{Min: math.Inf(-1), Symbol: "--"},
+	{Min: -10, Symbol: "-"},
+	{Min: 0, Symbol: "+"},
+	{Min: 10, Symbol: "++"},
+}}
+
+// GradePolicy scores the new coverage with letter grades.
+var GradePolicy = ThresholdPolicy{Coverage: true, Levels: []ScoreLevel{
+	{Min: 90, Symbol: "A"},
+	{Min: 80, Symbol: "B"},
+	{Min: 70, Symbol: "C"},
+	{Min: 60, Symbol: "D"},
+	{Min: 0, Symbol: "F"},
+}}
+
+// ParseScoringPolicy parses a scoring policy. It is either one of the
+// predefined policies "emoji", "text" and "grade", an EmojiPolicy with custom
+// thresholds (e.g. "emoji:-5,5,15" for the skull, tada and star thresholds)
+// or a ThresholdPolicy of the coverage change (e.g. "delta:-inf=worse,0=better")
+// or the new coverage (e.g. "coverage:80=good,0=bad").
+func ParseScoringPolicy(s string) (ScoringPolicy, error) {
+	kind, spec, _ := strings.Cut(s, ":")
+	switch kind {
+	case "emoji":
+		if spec == "" {
+			return DefaultScoringPolicy, nil
+		}
+		return parseEmojiPolicy(spec)
+	case "text", "grade":
+		if spec != "" {
+			return nil, fmt.Errorf("scoring policy %q does not take any arguments", kind)
+		}
+		if kind == "text" {
+			return TextPolicy, nil
+		}
+		return GradePolicy, nil
+	case "delta", "coverage":
+		levels, err := parseScoreLevels(spec)
+		if err != nil {
+			return nil, err
+		}
+		return ThresholdPolicy{Coverage: kind == "coverage", Levels: levels}, nil
+	default:
+		return nil, fmt.Errorf("unknown scoring policy %q", kind)
+	}
+}
+
+func parseEmojiPolicy(spec string) (ScoringPolicy, error) {
+	parts := strings.Split(spec, ",")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid emoji thresholds %q: expected skull,tada,star", spec)
+	}
+
+	var values [3]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid emoji thresholds %q: %w", spec, err)
+		}
+		values[i] = v
+	}
+
+	if values[0] >= 0 {
+		return nil, fmt.Errorf("invalid emoji thresholds %q: skull threshold must be negative", spec)
+	}
+
+	return EmojiPolicy{Thresholds: Thresholds{Skull: values[0], Tada: values[1], Star: values[2]}}, nil
+}
+
+func parseScoreLevels(spec string) ([]ScoreLevel, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("missing score levels")
+	}
+
+	var levels []ScoreLevel
+	for _, part := range strings.Split(spec, ",") {
+		minimum, symbol, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid score level %q: expected min=symbol", part)
+		}
+
+		v, err := strconv.ParseFloat(minimum, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid score level %q: %w", part, err)
+		}
+
+		levels = append(levels, ScoreLevel{Min: v, Symbol: symbol})
+	}
+
+	return levels, nil
+}
+
+// deltaText formats the coverage change as in the default report.
+func deltaText(newPercent, oldPercent float64) string {
+	diff := newPercent - oldPercent
+	if diff == 0 {
+		return "ø"
+	}
+
+	return fmt.Sprintf("**%+.2f%%**", diff)
+}
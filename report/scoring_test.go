@@ -0,0 +1,100 @@
+package report
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmojiPolicy_Score(t *testing.T) {
+	cases := map[float64]string{
+		-60: ":skull: :skull: :skull: :skull: :skull: ",
+		-25: ":skull: :skull: ",
+		-5:  ":thumbsdown:",
+		0:   "",
+		5:   ":thumbsup:",
+		15:  ":tada:",
+		25:  ":star2:",
+	}
+
+	for delta, expected := range cases {
+		assert.Equal(t, expected, DefaultScoringPolicy.Score(50+delta, 50), "delta %v", delta)
+	}
+
+	strict := EmojiPolicy{Thresholds: Thresholds{Skull: -2, Tada: 2, Star: 5}}
+	assert.Equal(t, ":skull: :skull: ", strict.Score(45, 50))
+	assert.Equal(t, ":tada:", strict.Score(53, 50))
+}
+
+func TestThresholdPolicy_Score(t *testing.T) {
+	assert.Equal(t, "--", TextPolicy.Score(20, 50))
+	assert.Equal(t, "-", TextPolicy.Score(45, 50))
+	assert.Equal(t, "", TextPolicy.Score(50.001, 50))
+	assert.Equal(t, "+", TextPolicy.Score(55, 50))
+	assert.Equal(t, "++", TextPolicy.Score(60, 50))
+
+	assert.Equal(t, "A", GradePolicy.Score(95, 50))
+	assert.Equal(t, "B", GradePolicy.Score(80, 90))
+	assert.Equal(t, "F", GradePolicy.Score(10, 10))
+
+	assert.Equal(t, "", ThresholdPolicy{Levels: []ScoreLevel{{Min: 5, Symbol: "x"}}}.Score(52, 50))
+}
+
+func TestParseScoringPolicy(t *testing.T) {
+	p, err := ParseScoringPolicy("emoji")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultScoringPolicy, p)
+
+	p, err = ParseScoringPolicy("emoji:-5,5,15")
+	require.NoError(t, err)
+	assert.Equal(t, EmojiPolicy{Thresholds: Thresholds{Skull: -5, Tada: 5, Star: 15}}, p)
+
+	p, err = ParseScoringPolicy("grade")
+	require.NoError(t, err)
+	assert.Equal(t, GradePolicy, p)
+
+	p, err = ParseScoringPolicy("delta:-inf=:warning:,0=:white_check_mark:")
+	require.NoError(t, err)
+	assert.Equal(t, ThresholdPolicy{Levels: []ScoreLevel{
+		{Min: math.Inf(-1), Symbol: ":warning:"},
+		{Min: 0, Symbol: ":white_check_mark:"},
+	}}, p)
+
+	p, err = ParseScoringPolicy("coverage:80=good,0=bad")
+	require.NoError(t, err)
+	assert.Equal(t, "bad", p.Score(79, 90))
+
+	_, err = ParseScoringPolicy("emoji:5,10,20")
+	assert.EqualError(t, err, `invalid emoji thresholds "5,10,20": skull threshold must be negative`)
+
+	_, err = ParseScoringPolicy("delta:good")
+	assert.EqualError(t, err, `invalid score level "good": expected min=symbol`)
+
+	_, err = ParseScoringPolicy("stars")
+	assert.EqualError(t, err, `unknown scoring policy "stars"`)
+}
+
+func TestReport_Markdown_Scoring(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, []string{"github.com/fgrosse/prioqueue/min_heap.go"})
+	report.TrimPrefix("github.com/fgrosse/")
+	report.Scoring = GradePolicy
+
+	expected := "### Merging this branch will **decrease** overall coverage A\n" +
+		"\n" +
+		"| Impacted Packages | Coverage Δ | :robot: |\n" +
+		"|-------------------|------------|---------|\n" +
+		"| prioqueue | 90.20% (**-9.80%**) | A |\n"
+	assert.True(t, strings.HasPrefix(report.Markdown(), expected))
+	assert.Contains(t, report.Markdown(), "| prioqueue/min_heap.go | 80.77% (**-19.23%**) | 52 (+2) | 42 (-8) | 10 (+10) | B |\n")
+	assert.Equal(t, Thresholds{}, report.TemplateData().Thresholds)
+}
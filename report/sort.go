@@ -0,0 +1,126 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// SortOrder determines the order of the packages and files of a report.
+type SortOrder string
+
+// The supported sort orders. Rows with equal values keep their order by name.
+const (
+	// SortByName sorts the rows alphabetically by their name.
+	SortByName SortOrder = "name"
+	// SortByDelta sorts the rows by their coverage change, largest decrease first.
+	SortByDelta SortOrder = "delta"
+	// SortByCoverage sorts the rows by their new coverage, lowest first.
+	SortByCoverage SortOrder = "coverage"
+	// SortByMissed sorts the rows by their new number of missed statements,
+	// largest first.
+	SortByMissed SortOrder = "missed"
+	// SortByChanged sorts the rows by the number of statements that were
+	// added or removed, largest first.
+	SortByChanged SortOrder = "changed"
+)
+
+// ParseSortOrder parses one of "name", "delta", "coverage", "missed" and
+// "changed" into a SortOrder.
+func ParseSortOrder(s string) (SortOrder, error) {
+	switch o := SortOrder(s); o {
+	case SortByName, SortByDelta, SortByCoverage, SortByMissed, SortByChanged:
+		return o, nil
+	default:
+		return "", fmt.Errorf("unknown sort order %q", s)
+	}
+}
+
+// sortOrder returns the configured SortOrder of the report. Reports of full
+// profiles are sorted by delta and all other reports by name by default.
+func (r *Report) sortOrder() SortOrder {
+	switch {
+	case r.Sort != "":
+		return r.Sort
+	case r.Full:
+		return SortByDelta
+	default:
+		return SortByName
+	}
+}
+
+// sortDeltas sorts the given deltas, which are expected to be sorted by
+// name, in the given order.
+func sortDeltas(deltas []CoverageDelta, order SortOrder) {
+	switch order {
+	case SortByDelta:
+		sortByDelta(deltas)
+	case SortByCoverage:
+		slices.SortStableFunc(deltas, func(a, b CoverageDelta) int {
+			return cmp.Compare(round(a.New.Percent, 2), round(b.New.Percent, 2))
+		})
+	case SortByMissed:
+		slices.SortStableFunc(deltas, func(a, b CoverageDelta) int {
+			return cmp.Compare(b.New.Missed, a.New.Missed)
+		})
+	case SortByChanged:
+		slices.SortStableFunc(deltas, func(a, b CoverageDelta) int {
+			return cmp.Compare(changedStatements(b), changedStatements(a))
+		})
+	}
+}
+
+// changedStatements returns the number of statements that were added to or
+// removed from a package or file.
+func changedStatements(d CoverageDelta) int64 {
+	n := d.New.Total - d.Old.Total
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// filterRows removes the rows that are hidden by the HideUnchanged and
+// MinStatements options of the report. It must be called after the
+// directories and owners were computed from all packages and files so that
+// hiding rows does not change their coverage. Directories and owners without
+// visible rows are removed.
+func (r *Report) filterRows(data *TemplateData) {
+	data.Packages = r.filterDeltas(data.Packages)
+	data.IndirectPackages = r.filterDeltas(data.IndirectPackages)
+	data.CodeFiles = r.filterDeltas(data.CodeFiles)
+	data.TopGains = r.filterDeltas(data.TopGains)
+	data.TopLosses = r.filterDeltas(data.TopLosses)
+
+	directories := data.Directories[:0]
+	for _, d := range data.Directories {
+		d.Packages = r.filterDeltas(d.Packages)
+		if len(d.Packages) > 0 {
+			directories = append(directories, d)
+		}
+	}
+	data.Directories = directories
+
+	owners := data.Owners[:0]
+	for _, o := range data.Owners {
+		o.Files = r.filterDeltas(o.Files)
+		if len(o.Files) > 0 {
+			owners = append(owners, o)
+		}
+	}
+	data.Owners = owners
+}
+
+// filterDeltas removes the deltas that are hidden by the HideUnchanged and
+// MinStatements options of the report.
+func (r *Report) filterDeltas(deltas []CoverageDelta) []CoverageDelta {
+	if !r.HideUnchanged && r.MinStatements <= 0 {
+		return deltas
+	}
+
+	return slices.DeleteFunc(deltas, func(d CoverageDelta) bool {
+		return r.HideUnchanged && d.Status == "unchanged" ||
+			max(d.Old.Total, d.New.Total) < r.MinStatements
+	})
+}
@@ -0,0 +1,136 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Sort(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/01-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	names := func(deltas []CoverageDelta) []string {
+		var result []string
+		for _, d := range deltas {
+			result = append(result, d.Name)
+		}
+		return result
+	}
+
+	cases := map[string]struct {
+		setup    func(r *Report)
+		packages []string
+		files    []string
+	}{
+		"name": {
+			setup:    func(*Report) {},
+			packages: []string{"github.com/fgrosse/prioqueue", "github.com/fgrosse/prioqueue/foo/bar"},
+			files:    []string{"github.com/fgrosse/prioqueue/foo/bar/baz.go", "github.com/fgrosse/prioqueue/min_heap.go"},
+		},
+		"delta": {
+			setup:    func(r *Report) { r.Sort = SortByDelta },
+			packages: []string{"github.com/fgrosse/prioqueue", "github.com/fgrosse/prioqueue/foo/bar"},
+			files:    []string{"github.com/fgrosse/prioqueue/min_heap.go", "github.com/fgrosse/prioqueue/foo/bar/baz.go"},
+		},
+		"coverage": {
+			setup:    func(r *Report) { r.Sort = SortByCoverage },
+			packages: []string{"github.com/fgrosse/prioqueue/foo/bar", "github.com/fgrosse/prioqueue"},
+			files:    []string{"github.com/fgrosse/prioqueue/foo/bar/baz.go", "github.com/fgrosse/prioqueue/min_heap.go"},
+		},
+		"missed": {
+			setup:    func(r *Report) { r.Sort = SortByMissed },
+			packages: []string{"github.com/fgrosse/prioqueue", "github.com/fgrosse/prioqueue/foo/bar"},
+			files:    []string{"github.com/fgrosse/prioqueue/min_heap.go", "github.com/fgrosse/prioqueue/foo/bar/baz.go"},
+		},
+		"changed": {
+			setup:    func(r *Report) { r.Sort = SortByChanged },
+			packages: []string{"github.com/fgrosse/prioqueue", "github.com/fgrosse/prioqueue/foo/bar"},
+			files:    []string{"github.com/fgrosse/prioqueue/min_heap.go", "github.com/fgrosse/prioqueue/foo/bar/baz.go"},
+		},
+		"hide unchanged": {
+			setup:    func(r *Report) { r.HideUnchanged = true },
+			packages: []string{"github.com/fgrosse/prioqueue"},
+			files:    []string{"github.com/fgrosse/prioqueue/min_heap.go"},
+		},
+		"min statements": {
+			setup:    func(r *Report) { r.MinStatements = 51 },
+			packages: []string{"github.com/fgrosse/prioqueue"},
+			files:    []string{"github.com/fgrosse/prioqueue/min_heap.go"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			report := New(oldCov, newCov, changedFiles)
+			c.setup(report)
+
+			data := report.TemplateData()
+			assert.Equal(t, c.packages, names(data.Packages))
+			assert.Equal(t, c.files, names(data.CodeFiles))
+		})
+	}
+}
+
+func TestReport_HideUnchanged_Owners(t *testing.T) {
+	oldCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/repo/a/a.go:1.1,2.2 1 0
+example.com/repo/a/a.go:3.1,4.2 1 1
+example.com/repo/c/c.go:1.1,2.2 2 1
+`), nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/repo/a/a.go:1.1,2.2 1 1
+example.com/repo/a/a.go:3.1,4.2 1 1
+example.com/repo/c/c.go:1.1,2.2 2 1
+`), nil)
+	require.NoError(t, err)
+
+	owners, err := ReadCodeOwners(strings.NewReader("/a/ @org/a\n/c/ @org/c\n"))
+	require.NoError(t, err)
+	owners.Root = "example.com/repo"
+
+	newReport := func(hide bool) *Report {
+		report := New(oldCov, newCov, []string{"example.com/repo/a/a.go", "example.com/repo/c/c.go"})
+		report.CodeOwners = owners
+		report.HideUnchanged = hide
+		return report
+	}
+
+	data := newReport(true).TemplateData()
+	require.Len(t, data.Owners, 1)
+	assert.Equal(t, "@org/a", data.Owners[0].Owner)
+	assert.Len(t, data.CodeFiles, 1)
+
+	doc := newReport(true).JSONReport()
+	require.Len(t, doc.Owners, 1)
+	assert.Equal(t, "@org/a", doc.Owners[0].Owner)
+	assert.Equal(t, []string{"example.com/repo/a/a.go"}, doc.Owners[0].Files)
+	assert.NotContains(t, newReport(true).Markdown(), "@org/c")
+
+	expected, actual := new(bytes.Buffer), new(bytes.Buffer)
+	require.NoError(t, newReport(false).WriteMetrics(expected))
+	require.NoError(t, newReport(true).WriteMetrics(actual))
+	assert.Equal(t, expected.String(), actual.String())
+	assert.Contains(t, actual.String(), `owner_coverage={"@org/a":100,"@org/c":100}`+"\n")
+}
+
+func TestParseSortOrder(t *testing.T) {
+	order, err := ParseSortOrder("missed")
+	require.NoError(t, err)
+	assert.Equal(t, SortByMissed, order)
+
+	_, err = ParseSortOrder("size")
+	assert.EqualError(t, err, `unknown sort order "size"`)
+}
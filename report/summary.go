@@ -0,0 +1,97 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// Summary describes the coverage of a single profile by package.
+type Summary struct {
+	Title    string // rendered as Markdown heading
+	Coverage *coverage.Coverage
+}
+
+// JSONSummaryReport is the JSON document of a Summary.
+type JSONSummaryReport struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Total         JSONValues           `json:"total"`
+	Packages      []JSONPackageSummary `json:"packages"`
+}
+
+// JSONPackageSummary contains the coverage of a single package of a Summary.
+type JSONPackageSummary struct {
+	Name string `json:"name"`
+	JSONValues
+}
+
+// NewSummary creates a Summary of the given coverage.
+func NewSummary(cov *coverage.Coverage) *Summary {
+	return &Summary{Title: "Coverage summary", Coverage: cov}
+}
+
+// Markdown renders the summary as Markdown table.
+func (s *Summary) Markdown() string {
+	report := new(strings.Builder)
+
+	fmt.Fprintf(report, "### %s\n", s.Title)
+	fmt.Fprintln(report)
+	fmt.Fprintln(report, "| Package | Coverage | Total | Covered | Missed |")
+	fmt.Fprintln(report, "|---------|----------|-------|---------|--------|")
+
+	pkgs := s.Coverage.ByPackage()
+	for _, name := range sortedKeys(pkgs) {
+		cov := pkgs[name]
+		fmt.Fprintf(report, "| %s | %.2f%% | %d | %d | %d |\n",
+			name, cov.Percent(), cov.TotalStmt, cov.CoveredStmt, cov.MissedStmt,
+		)
+	}
+
+	fmt.Fprintf(report, "| **Total** | **%.2f%%** | **%d** | **%d** | **%d** |",
+		s.Coverage.Percent(), s.Coverage.TotalStmt, s.Coverage.CoveredStmt, s.Coverage.MissedStmt,
+	)
+
+	return report.String()
+}
+
+// JSONReport returns the JSON document of the summary.
+func (s *Summary) JSONReport() JSONSummaryReport {
+	doc := JSONSummaryReport{
+		SchemaVersion: JSONSchemaVersion,
+		Total:         jsonValues(coverageValues(s.Coverage)),
+	}
+
+	pkgs := s.Coverage.ByPackage()
+	doc.Packages = make([]JSONPackageSummary, 0, len(pkgs))
+	for _, name := range sortedKeys(pkgs) {
+		doc.Packages = append(doc.Packages, JSONPackageSummary{
+			Name:       name,
+			JSONValues: jsonValues(coverageValues(pkgs[name])),
+		})
+	}
+
+	return doc
+}
+
+// JSON returns the JSON document of the summary as string.
+func (s *Summary) JSON() string {
+	data, err := json.MarshalIndent(s.JSONReport(), "", "    ")
+	if err != nil {
+		panic(err) // should never happen
+	}
+
+	return string(data)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary_Markdown(t *testing.T) {
+	cov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	cov.TrimPrefix("github.com/fgrosse/")
+	actual := NewSummary(cov).Markdown()
+
+	expected := `### Coverage summary
+
+| Package | Coverage | Total | Covered | Missed |
+|---------|----------|-------|---------|--------|
+| prioqueue | 90.20% | 102 | 92 | 10 |
+| **Total** | **90.20%** | **102** | **92** | **10** |`
+	assert.Equal(t, expected, actual)
+}
+
+func TestSummary_JSON(t *testing.T) {
+	cov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	expected := `{
+		"schema_version": 1,
+		"total": {"percent": 90.2, "total_statements": 102, "covered_statements": 92, "missed_statements": 10},
+		"packages": [
+			{"name": "github.com/fgrosse/prioqueue", "percent": 90.2, "total_statements": 102, "covered_statements": 92, "missed_statements": 10}
+		]
+	}`
+	assert.JSONEq(t, expected, NewSummary(cov).JSON())
+}
@@ -0,0 +1,484 @@
+package report
+
+import (
+	"cmp"
+	_ "embed" // needed for the default markdown template
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+//go:embed templates/markdown.tmpl
+var defaultTemplate string
+
+// TemplateFuncs contains the helper functions that are available in all
+// report templates in addition to the builtin text/template functions.
+// When a report is rendered, "emoji" scores with the scoring policy of the
+// report instead of the DefaultScoringPolicy.
+var TemplateFuncs = template.FuncMap{
+	"percent":     formatPercent,
+	"join":        strings.Join,
+	"delta":       formatDelta,
+	"withDelta":   valueWithDelta,
+	"shortCommit": shortCommit,
+	"indent":      indent,
+	"emoji":       DefaultScoringPolicy.Score,
+}
+
+// TemplateData is the view model that is passed to the report templates.
+// Its fields are considered a stable API so that users can write their own
+// templates without depending on the internal report types.
+type TemplateData struct {
+	// Title is the headline of the default report without any Markdown
+	// heading prefix (e.g. "Merging this branch will **increase** overall coverage").
+	Title string
+	// Trend is one of "increase", "decrease", "no change" or "mixed" and
+	// summarizes how the coverage of the changed packages changed.
+	Trend string
+	// NumIncrease and NumDecrease count the changed packages whose coverage
+	// increased or decreased respectively.
+	NumIncrease, NumDecrease int
+
+	// Total contains the coverage of the entire old and new profiles.
+	Total CoverageDelta
+	// Packages contains one entry per changed package in the Sort order of
+	// the report (by name or, if the report compares full profiles, by Delta,
+	// largest decrease first). Packages that are hidden by the HideUnchanged
+	// or MinStatements options of the report are omitted.
+	Packages []CoverageDelta
+	// Directories contains the Packages grouped by their directory if the
+	// RollupDepth of the report is set, in the order of their first package.
+	// The Tree of each directory contains the combined coverage of its
+	// subdirectories.
+	Directories []DirectoryDelta
+	// Components contains the coverage of each component of the report in
+	// the order in which they were configured.
+	Components []CoverageDelta
+	// TopGains and TopLosses contain the packages with the largest coverage
+	// increase and decrease respectively, largest change first. They are
+	// empty unless the TopMovers of the report are set.
+	TopGains, TopLosses []CoverageDelta
+	// IndirectPackages contains one entry per package without changed files
+	// whose coverage changed by more than the IndirectThreshold of the report,
+	// filtered and sorted like Packages. It is empty unless the threshold is set.
+	IndirectPackages []CoverageDelta
+	// CodeFiles contains one entry per changed non-test Go file filtered and
+	// sorted like Packages.
+	CodeFiles []CoverageDelta
+	// TestFiles contains the names of all changed unit test files.
+	TestFiles []string
+	// Owners contains the coverage of each code owner of the changed files
+	// sorted by name. It is empty unless the CodeOwners of the report are set.
+	Owners []OwnerCoverage
+	// History contains the coverage trend of the packages in the previous
+	// commits of the history file. It is nil if no history was given.
+	History *HistoryTrend
+	// Labels contains the names of all labeled profiles (e.g. "unit" or
+	// "integration") in the order in which they were added to the report.
+	Labels []string
+	// Platforms contains the sorted names of all platforms that contributed
+	// to the new coverage if profiles of a build matrix were used.
+	Platforms []string
+
+	// ScoreHeader is the table header of the score column. It is ":robot:"
+	// unless the report is accessible.
+	ScoreHeader string
+	// Accessible is true if the report should be rendered without emojis.
+	Accessible bool
+
+	// Truncated is true if details were omitted because the report exceeded
+	// its MaxLength of characters. OmittedPackages, OmittedFiles and
+	// OmittedTestFiles count the rows that were removed from the respective
+	// tables and lists.
+	Truncated        bool
+	MaxLength        int
+	OmittedPackages  int
+	OmittedFiles     int
+	OmittedTestFiles int
+
+	// Thresholds documents the coverage changes at which the emojis change.
+	// It is empty if the report uses a scoring policy other than EmojiPolicy.
+	Thresholds Thresholds
+	// Metadata describes the inputs that were used to generate the report.
+	Metadata Metadata
+}
+
+// CoverageDelta describes the coverage of a package, a file or the total
+// profile in the old and the new version of the code.
+type CoverageDelta struct {
+	// Name is the package or file name. It is empty for the total coverage.
+	Name string
+	Old  CoverageValues
+	New  CoverageValues
+	// Delta is the difference of the new and old coverage in percentage points.
+	Delta float64
+	// DeltaText is Delta formatted as in the default report (e.g. "**+1.23%**"
+	// or "ø") or as in accessible reports (e.g. "▲ 1.23 pp" or "no change").
+	DeltaText string
+	// Status is one of "increased", "decreased" or "unchanged".
+	Status string
+	// Emoji is the score of the coverage change according to the scoring
+	// policy of the report (emojis by default, see Thresholds).
+	Emoji string
+	// Labels contains the coverage of the same package or profile in each
+	// labeled profile. It is only set for Total and Packages.
+	Labels []LabelCoverage
+	// Platforms contains the platforms whose new profiles contain the file.
+	// It is only set for CodeFiles.
+	Platforms []string
+	// Owners contains the code owners of a file or of the changed files of a
+	// package. It is only set for Packages and CodeFiles if the report has
+	// CodeOwners.
+	Owners []string
+}
+
+// LabelCoverage is the coverage of a package or profile in a labeled profile.
+type LabelCoverage struct {
+	Label string
+	CoverageDelta
+}
+
+// CoverageValues contains the statement counts and the coverage percentage
+// of a single package, file or profile.
+type CoverageValues struct {
+	Percent float64
+	Total   int64
+	Covered int64
+	Missed  int64
+}
+
+// Thresholds are the coverage changes in percentage points at which the
+// score of an EmojiPolicy changes. Skull is negative and every full multiple of it
+// adds another skull (up to five).
+type Thresholds struct {
+	Skull float64
+	Tada  float64
+	Star  float64
+}
+
+// Metadata describes the tool and the inputs that were used to create a report.
+type Metadata struct {
+	Version          string
+	OldProfile       string
+	NewProfile       string
+	ChangedFilesFile string
+	Root             string
+	Trim             string
+
+	// BaselineCommit is the commit of the old profile if it was resolved
+	// from a baseline store. BaselineDistance is the number of commits it
+	// is older than the MergeBase of the compared branch.
+	BaselineCommit   string
+	MergeBase        string
+	BaselineDistance int
+
+	// FullReportURL links to the complete report (e.g. the summary of the CI
+	// job) if details were omitted to keep the report below its MaxLength.
+	FullReportURL string
+}
+
+// ParseTemplate reads and parses a text/template from the given file.
+// The parsed template has access to the TemplateFuncs.
+func ParseTemplate(filename string) (*template.Template, error) {
+	tmpl, err := template.New(filepath.Base(filename)).Funcs(TemplateFuncs).ParseFiles(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// Template renders the report using the given template.
+// If the result is longer than the MaxLength of the report, details are
+// omitted until it fits.
+func (r *Report) Template(tmpl *template.Template) (string, error) {
+	tmpl, err := r.bindFuncs(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	data := r.TemplateData()
+	out, err := executeTemplate(tmpl, data)
+	if err != nil || r.MaxLength <= 0 || utf8.RuneCountInString(out) <= r.MaxLength {
+		return out, err
+	}
+
+	return r.truncate(tmpl, data)
+}
+
+// Render executes the given template with the TemplateData of the report
+// and writes the result to w.
+func (r *Report) Render(w io.Writer, tmpl *template.Template) error {
+	if r.MaxLength > 0 {
+		out, err := r.Template(tmpl)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w, out)
+		return err
+	}
+
+	tmpl, err := r.bindFuncs(tmpl)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, r.TemplateData())
+}
+
+// bindFuncs returns a copy of the template whose "emoji" function scores
+// with the scoring policy of the report.
+func (r *Report) bindFuncs(tmpl *template.Template) (*template.Template, error) {
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	return clone.Funcs(template.FuncMap{"emoji": r.scoring().Score}), nil
+}
+
+func executeTemplate(tmpl *template.Template, data TemplateData) (string, error) {
+	out := new(strings.Builder)
+	err := tmpl.Execute(out, data)
+	if err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// TemplateData returns the view model of the report that is passed to templates.
+func (r *Report) TemplateData() TemplateData {
+	data := TemplateData{
+		Total:       r.newCoverageDelta("", coverageValues(r.Old), coverageValues(r.New)),
+		ScoreHeader: ":robot:",
+		Accessible:  r.Accessible,
+		Metadata:    r.Metadata,
+	}
+
+	if r.Accessible {
+		data.ScoreHeader = "Score"
+	}
+
+	if p, ok := r.scoring().(EmojiPolicy); ok {
+		data.Thresholds = p.Thresholds
+	}
+
+	type labelPackages struct {
+		name     string
+		old, new map[string]*coverage.Coverage
+	}
+
+	labels := make([]labelPackages, 0, len(r.Labels))
+	for _, l := range r.Labels {
+		data.Labels = append(data.Labels, l.Name)
+		data.Total.Labels = append(data.Total.Labels, LabelCoverage{
+			Label:         l.Name,
+			CoverageDelta: r.newCoverageDelta("", coverageValues(l.Old), coverageValues(l.New)),
+		})
+		labels = append(labels, labelPackages{name: l.Name, old: l.Old.ByPackage(), new: l.New.ByPackage()})
+	}
+
+	oldCovPkgs := r.Old.ByPackage()
+	newCovPkgs := r.New.ByPackage()
+	for _, pkg := range r.ChangedPackages {
+		d := r.newCoverageDelta(pkg, coverageValues(oldCovPkgs[pkg]), coverageValues(newCovPkgs[pkg]))
+		for _, l := range labels {
+			d.Labels = append(d.Labels, LabelCoverage{
+				Label:         l.name,
+				CoverageDelta: r.newCoverageDelta(pkg, coverageValues(l.old[pkg]), coverageValues(l.new[pkg])),
+			})
+		}
+		if r.CodeOwners != nil {
+			d.Owners = r.packageOwners(pkg)
+		}
+		data.Packages = append(data.Packages, d)
+	}
+
+	for _, pkg := range r.IndirectPackages() {
+		d := r.newCoverageDelta(pkg, coverageValues(oldCovPkgs[pkg]), coverageValues(newCovPkgs[pkg]))
+		data.IndirectPackages = append(data.IndirectPackages, d)
+	}
+
+	for _, f := range r.ChangedFiles {
+		if strings.HasSuffix(f, "_test.go") {
+			data.TestFiles = append(data.TestFiles, f)
+			continue
+		}
+
+		d := r.newCoverageDelta(f, profileValues(r.Old.Files[f]), profileValues(r.New.Files[f]))
+		if p := r.New.Files[f]; p != nil {
+			d.Platforms = p.Platforms
+		}
+		if r.CodeOwners != nil {
+			d.Owners = r.CodeOwners.Owners(f)
+		}
+		data.CodeFiles = append(data.CodeFiles, d)
+	}
+
+	order := r.sortOrder()
+	sortDeltas(data.Packages, order)
+	sortDeltas(data.IndirectPackages, order)
+	sortDeltas(data.CodeFiles, order)
+
+	data.TopGains, data.TopLosses = topMovers(data.Packages, r.TopMovers)
+	data.Directories = r.directories(data.Packages)
+	data.Owners = r.owners(data.CodeFiles)
+	data.Components = r.components()
+	r.filterRows(&data)
+	for i, d := range data.Directories {
+		data.Directories[i].Tree = r.directoryTree(d)
+	}
+
+	if len(r.History) > 0 {
+		data.History = &HistoryTrend{
+			Commits: len(r.History),
+			Total:   r.packageHistory("", data.Total.New.Percent),
+		}
+		for _, pkg := range data.Packages {
+			data.History.Packages = append(data.History.Packages, r.packageHistory(pkg.Name, pkg.New.Percent))
+		}
+	}
+
+	for _, p := range r.New.Files {
+		for _, platform := range p.Platforms {
+			if !slices.Contains(data.Platforms, platform) {
+				data.Platforms = append(data.Platforms, platform)
+			}
+		}
+	}
+	slices.Sort(data.Platforms)
+
+	data.NumIncrease, data.NumDecrease = r.countChanges()
+	data.Trend = trend(data.NumIncrease, data.NumDecrease)
+	data.Title = r.headline()
+
+	return data
+}
+
+// newCoverageDelta creates a CoverageDelta that is scored and formatted
+// according to the scoring policy and accessibility of the report.
+func (r *Report) newCoverageDelta(name string, oldVal, newVal CoverageValues) CoverageDelta {
+	return scoredCoverageDelta(r.scoring(), r.Accessible, name, oldVal, newVal)
+}
+
+func scoredCoverageDelta(policy ScoringPolicy, accessible bool, name string, oldVal, newVal CoverageValues) CoverageDelta {
+	d := CoverageDelta{
+		Name:      name,
+		Old:       oldVal,
+		New:       newVal,
+		Delta:     newVal.Percent - oldVal.Percent,
+		DeltaText: deltaText(newVal.Percent, oldVal.Percent),
+		Emoji:     policy.Score(newVal.Percent, oldVal.Percent),
+	}
+
+	if accessible {
+		d.DeltaText = accessibleDeltaText(newVal.Percent, oldVal.Percent)
+	}
+
+	newP, oldP := round(newVal.Percent, 2), round(oldVal.Percent, 2)
+	switch {
+	case newP > oldP:
+		d.Status = "increased"
+	case newP < oldP:
+		d.Status = "decreased"
+	default:
+		d.Status = "unchanged"
+	}
+
+	return d
+}
+
+// sortByDelta sorts the given deltas by their rounded Delta in ascending
+// order so that the largest decrease comes first. Equal deltas keep their order.
+func sortByDelta(deltas []CoverageDelta) {
+	slices.SortStableFunc(deltas, func(a, b CoverageDelta) int {
+		return cmp.Compare(round(a.Delta, 2), round(b.Delta, 2))
+	})
+}
+
+// topMovers returns up to n of the given deltas with the largest increase
+// and decrease respectively, largest change first.
+func topMovers(deltas []CoverageDelta, n int) (gains, losses []CoverageDelta) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	for _, d := range deltas {
+		switch d.Status {
+		case "increased":
+			gains = append(gains, d)
+		case "decreased":
+			losses = append(losses, d)
+		}
+	}
+
+	slices.SortStableFunc(gains, func(a, b CoverageDelta) int {
+		return cmp.Compare(round(b.Delta, 2), round(a.Delta, 2))
+	})
+	sortByDelta(losses)
+
+	return gains[:min(n, len(gains))], losses[:min(n, len(losses))]
+}
+
+func coverageValues(c *coverage.Coverage) CoverageValues {
+	if c == nil {
+		return CoverageValues{}
+	}
+
+	return CoverageValues{
+		Percent: c.Percent(),
+		Total:   c.TotalStmt,
+		Covered: c.CoveredStmt,
+		Missed:  c.MissedStmt,
+	}
+}
+
+func profileValues(p *coverage.Profile) CoverageValues {
+	return CoverageValues{
+		Percent: p.CoveragePercent(),
+		Total:   p.GetTotal(),
+		Covered: p.GetCovered(),
+		Missed:  p.GetMissed(),
+	}
+}
+
+// indent indents a row of a Markdown table by the given depth.
+func indent(depth int) string {
+	return strings.Repeat("&nbsp;&nbsp;", depth)
+}
+
+func formatPercent(percent float64) string {
+	return fmt.Sprintf("%.2f%%", percent)
+}
+
+func formatDelta(delta float64) string {
+	if delta == 0 {
+		return "ø"
+	}
+
+	return fmt.Sprintf("%+.2f%%", delta)
+}
+
+func valueWithDelta(oldVal, newVal int64) string {
+	diff := oldVal - newVal
+	switch {
+	case diff < 0:
+		return fmt.Sprintf("%d (+%d)", newVal, -diff)
+	case diff > 0:
+		return fmt.Sprintf("%d (-%d)", newVal, diff)
+	default:
+		return fmt.Sprintf("%d", newVal)
+	}
+}
+
+func mustParseDefaultTemplate() *template.Template {
+	return template.Must(template.New("markdown.tmpl").Funcs(TemplateFuncs).Parse(defaultTemplate))
+}
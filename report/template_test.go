@@ -0,0 +1,68 @@
+package report
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Template(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/01-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, changedFiles)
+	report.Metadata.Version = "v1.2.3"
+
+	tmpl, err := ParseTemplate("testdata/04-custom-template.tmpl")
+	require.NoError(t, err)
+
+	actual, err := report.Template(tmpl)
+	require.NoError(t, err)
+
+	expected := `Coverage decrease (0 up, 1 down) by go-coverage-report v1.2.3
+Total: 90.20% (-9.80%)
+* github.com/fgrosse/prioqueue: 90.20% (-9.80%) decreased
+* github.com/fgrosse/prioqueue/foo/bar: 0.00% (ø) unchanged
+- github.com/fgrosse/prioqueue/foo/bar/baz.go: 0 statements []
+- github.com/fgrosse/prioqueue/min_heap.go: 52 (+2) statements [:skull: ]
+Thresholds: -10 10 20
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestReport_Template_Error(t *testing.T) {
+	report := New(coverage.New(nil), coverage.New(nil), []string{"foo.go"})
+
+	tmpl := template.Must(template.New("test").Funcs(TemplateFuncs).Parse("{{ .DoesNotExist }}"))
+	_, err := report.Template(tmpl)
+	assert.Error(t, err)
+}
+
+func TestReport_Template_Emoji(t *testing.T) {
+	report := New(coverage.New(nil), coverage.New(nil), []string{"foo.go"})
+	tmpl := template.Must(template.New("test").Funcs(TemplateFuncs).Parse("{{ emoji 80 50 }}"))
+
+	actual, err := report.Template(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, ":star2:", actual)
+
+	report.Scoring = GradePolicy
+	actual, err = report.Template(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "B", actual)
+
+	report.Scoring = nil
+	report.Accessible = true
+	actual, err = report.Template(tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "major improvement", actual)
+}
@@ -0,0 +1,299 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI escape codes of the colored text output.
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+)
+
+// Text renders the report as aligned plain text tables for terminals. It
+// contains the same sections as the Markdown report including the per-file
+// details. If color is true, increases and decreases are highlighted with
+// ANSI colors.
+func (r *Report) Text(color bool) string {
+	data := r.TemplateData()
+	out := new(strings.Builder)
+
+	fmt.Fprintln(out, textTitle(data.Title, color))
+	writeTextBaseline(out, data.Metadata)
+
+	if len(data.TopGains) > 0 {
+		r.writeTextSection(out, "Top gains", "Package", data.TopGains, color)
+	}
+	if len(data.TopLosses) > 0 {
+		r.writeTextSection(out, "Top losses", "Package", data.TopLosses, color)
+	}
+
+	header := []string{"Impacted Packages"}
+	header = append(header, data.Labels...)
+	header = append(header, "Coverage Δ", "Score")
+
+	packages := &textTable{header: header}
+	addRow := func(name string, d CoverageDelta) {
+		row := []textCell{{text: name}}
+		for _, l := range d.Labels {
+			row = append(row, r.textDelta(l.CoverageDelta))
+		}
+		packages.rows = append(packages.rows, append(row, r.textDelta(d), r.textScore(d)))
+	}
+	if len(data.Directories) > 0 {
+		for _, d := range data.Directories {
+			name := d.Name
+			if d.Rollup {
+				name = fmt.Sprintf("%s/… (%s)", d.Name, numPackages(len(d.Packages)))
+			}
+			addRow(name, d.CoverageDelta)
+		}
+	} else {
+		for _, pkg := range data.Packages {
+			addRow(pkg.Name, pkg)
+		}
+	}
+	packages.write(out, color)
+
+	for _, d := range data.Directories {
+		if d.Rollup {
+			r.writeTextSection(out, d.Name, "Package", textTree(d.Tree), color)
+		}
+	}
+
+	if len(data.Components) > 0 {
+		r.writeTextSection(out, "Components", "Component", data.Components, color)
+	}
+
+	if len(data.IndirectPackages) > 0 {
+		r.writeTextSection(out, "Indirectly impacted packages", "Package", data.IndirectPackages, color)
+	}
+
+	if data.History != nil {
+		writeTextHistory(out, data.History, color)
+	}
+
+	for _, o := range data.Owners {
+		total := o.Total
+		total.Name = "Total"
+		r.writeTextSection(out, "Coverage of "+o.Owner, "Changed File", append(slices.Clip(o.Files), total), color)
+	}
+
+	if len(data.CodeFiles) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Changed files (no unit tests)")
+		header := []string{"Changed File", "Coverage Δ", "Total", "Covered", "Missed"}
+		if len(data.Platforms) > 0 {
+			header = append(header, "Platforms")
+		}
+		files := &textTable{header: append(header, "Score")}
+		for _, f := range data.CodeFiles {
+			row := []textCell{
+				{text: f.Name},
+				r.textDelta(f),
+				{text: valueWithDelta(f.Old.Total, f.New.Total)},
+				{text: valueWithDelta(f.Old.Covered, f.New.Covered)},
+				{text: valueWithDelta(f.Old.Missed, f.New.Missed)},
+			}
+			if len(data.Platforms) > 0 {
+				platforms := "–"
+				if len(f.Platforms) > 0 {
+					platforms = strings.Join(f.Platforms, ", ")
+				}
+				row = append(row, textCell{text: platforms})
+			}
+			files.rows = append(files.rows, append(row, r.textScore(f)))
+		}
+		files.write(out, color)
+	}
+
+	if len(data.TestFiles) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Changed unit test files")
+		for _, f := range data.TestFiles {
+			fmt.Fprintf(out, "  - %s\n", f)
+		}
+	}
+
+	return out.String()
+}
+
+// writeTextBaseline describes the baseline commit of the old profile if it
+// was resolved from a baseline store.
+func writeTextBaseline(out *strings.Builder, m Metadata) {
+	if m.BaselineCommit == "" {
+		return
+	}
+
+	fmt.Fprintln(out)
+	if m.BaselineDistance == 0 {
+		fmt.Fprintf(out, "Compared against the coverage of the merge base %s.\n", shortCommit(m.BaselineCommit))
+		return
+	}
+
+	commits := "commits"
+	if m.BaselineDistance == 1 {
+		commits = "commit"
+	}
+	fmt.Fprintf(out, "Warning: The baseline coverage is from commit %s which is %d %s older than the merge base %s. "+
+		"Coverage changes of these commits are included in the deltas below.\n",
+		shortCommit(m.BaselineCommit), m.BaselineDistance, commits, shortCommit(m.MergeBase))
+}
+
+func writeTextHistory(out *strings.Builder, h *HistoryTrend, color bool) {
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Coverage history (last %d commits)\n", h.Commits)
+
+	point := func(p *HistoryPoint) textCell {
+		if p == nil {
+			return textCell{text: "–"}
+		}
+		return textCell{text: fmt.Sprintf("%s (%s)", formatPercent(p.Percent), shortCommit(p.Commit))}
+	}
+
+	table := &textTable{header: []string{"Package", "Trend", "Best", "Worst"}}
+	for _, p := range append(slices.Clip(h.Packages), h.Total) {
+		name := p.Name
+		if name == "" {
+			name = "Total"
+		}
+		table.rows = append(table.rows, []textCell{{text: name}, {text: p.Sparkline}, point(p.Best), point(p.Worst)})
+	}
+	table.write(out, color)
+}
+
+// textTitle replaces the Markdown emphasis of the title with bold text or
+// removes it if color is false.
+func textTitle(title string, color bool) string {
+	bold, reset := "", ""
+	if color {
+		bold, reset = ansiBold, ansiReset
+	}
+
+	for strings.Count(title, "**") >= 2 {
+		title = strings.Replace(title, "**", bold, 1)
+		title = strings.Replace(title, "**", reset, 1)
+	}
+
+	return title
+}
+
+func (r *Report) writeTextSection(out *strings.Builder, title, nameHeader string, deltas []CoverageDelta, color bool) {
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, title)
+
+	table := &textTable{header: []string{nameHeader, "Coverage Δ", "Score"}}
+	for _, d := range deltas {
+		table.rows = append(table.rows, []textCell{{text: d.Name}, r.textDelta(d), r.textScore(d)})
+	}
+	table.write(out, color)
+}
+
+// textDelta formats the new coverage and its change without Markdown and
+// colors it by its status.
+func (r *Report) textDelta(d CoverageDelta) textCell {
+	delta := formatDelta(d.Delta)
+	if r.Accessible {
+		delta = d.DeltaText
+	}
+
+	cell := textCell{text: fmt.Sprintf("%s (%s)", formatPercent(d.New.Percent), delta)}
+	switch d.Status {
+	case "increased":
+		cell.color = ansiGreen
+	case "decreased":
+		cell.color = ansiRed
+	}
+
+	return cell
+}
+
+// textScore scores the change with the scoring policy of the report. Emoji
+// shortcodes are not rendered in terminals, so the TextPolicy is used unless
+// another policy is configured or the report is accessible.
+func (r *Report) textScore(d CoverageDelta) textCell {
+	policy := r.scoring()
+	if r.Scoring == nil && !r.Accessible {
+		policy = TextPolicy
+	}
+
+	return textCell{text: policy.Score(d.New.Percent, d.Old.Percent)}
+}
+
+type textTable struct {
+	header []string
+	rows   [][]textCell
+}
+
+type textCell struct {
+	text  string
+	color string
+}
+
+// write writes the table with left aligned columns that are separated by
+// two spaces. The padding only depends on the text of the cells so that
+// colors do not affect the alignment.
+func (t *textTable) write(out *strings.Builder, color bool) {
+	widths := make([]int, len(t.header))
+	for i, h := range t.header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range t.rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(c.text))
+		}
+	}
+
+	writeRow := func(cells []textCell) {
+		line := new(strings.Builder)
+		for i, c := range cells {
+			text := c.text
+			if color && c.color != "" {
+				text = c.color + text + ansiReset
+			}
+			line.WriteString(text)
+
+			if i < len(cells)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c.text)+2))
+			}
+		}
+		fmt.Fprintln(out, strings.TrimRight(line.String(), " "))
+	}
+
+	fmt.Fprintln(out)
+	header := make([]textCell, len(t.header))
+	for i, h := range t.header {
+		header[i] = textCell{text: h}
+	}
+	writeRow(header)
+
+	separator := make([]textCell, len(t.header))
+	for i, w := range widths {
+		separator[i] = textCell{text: strings.Repeat("-", w)}
+	}
+	writeRow(separator)
+
+	for _, row := range t.rows {
+		writeRow(row)
+	}
+}
+
+// textTree returns the rows of a directory tree with indented names. The rows
+// of subdirectories are marked like the rows of the directories.
+func textTree(tree []DirectoryNode) []CoverageDelta {
+	rows := make([]CoverageDelta, len(tree))
+	for i, n := range tree {
+		rows[i] = n.CoverageDelta
+		rows[i].Name = strings.Repeat("  ", n.Depth) + n.Name
+		if n.Packages > 0 {
+			rows[i].Name += fmt.Sprintf("/… (%s)", numPackages(n.Packages))
+		}
+	}
+
+	return rows
+}
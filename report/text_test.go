@@ -0,0 +1,131 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Text(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	report := New(oldCov, newCov, []string{
+		"github.com/fgrosse/prioqueue/min_heap.go",
+		"github.com/fgrosse/prioqueue/min_heap_test.go",
+		"github.com/fgrosse/other/other.go",
+	})
+	report.TrimPrefix("github.com/fgrosse/")
+
+	expected := `Merging this branch will decrease overall coverage
+
+Impacted Packages  Coverage Δ       Score
+-----------------  ---------------  -----
+other              0.00% (ø)
+prioqueue          90.20% (-9.80%)  -
+
+Changed files (no unit tests)
+
+Changed File           Coverage Δ        Total    Covered  Missed    Score
+---------------------  ----------------  -------  -------  --------  -----
+other/other.go         0.00% (ø)         0        0        0
+prioqueue/min_heap.go  80.77% (-19.23%)  52 (+2)  42 (-8)  10 (+10)  --
+
+Changed unit test files
+  - prioqueue/min_heap_test.go
+`
+	assert.Equal(t, expected, report.Text(false))
+
+	colored := report.Text(true)
+	assert.Contains(t, colored, "Merging this branch will \x1b[1mdecrease\x1b[0m overall coverage\n")
+	assert.Contains(t, colored, "prioqueue          \x1b[31m90.20% (-9.80%)\x1b[0m  -\n")
+}
+
+func TestReport_Text_Sections(t *testing.T) {
+	oldCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/repo/a/x/a.go:1.1,2.2 1 0
+example.com/repo/a/x/a.go:3.1,4.2 1 1
+example.com/repo/a/y/b.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/repo/a/x/a.go:1.1,2.2 1 1
+example.com/repo/a/x/a.go:3.1,4.2 1 1
+example.com/repo/a/y/b.go:1.1,2.2 1 0
+`), nil)
+	require.NoError(t, err)
+
+	owners, err := ReadCodeOwners(strings.NewReader("/a/ @org/a\n"))
+	require.NoError(t, err)
+	owners.Root = "example.com/repo"
+
+	report := NewFull(oldCov, newCov)
+	report.TopMovers = 1
+	report.RollupDepth = 2
+	report.CodeOwners = owners
+	report.History = []HistoryEntry{NewHistoryEntry("aaaaaaaaaa", time.Unix(0, 0), oldCov)}
+	report.Metadata.BaselineCommit = "bbbbbbbbbb"
+	report.Metadata.MergeBase = "cccccccccc"
+	report.Metadata.BaselineDistance = 2
+	report.TrimPrefix("example.com/")
+
+	expected := `The coverage changed in 2 packages (1 decrease, 1 increase)
+
+Warning: The baseline coverage is from commit bbbbbbb which is 2 commits older than the merge base ccccccc. Coverage changes of these commits are included in the deltas below.
+
+Top gains
+
+Package   Coverage Δ         Score
+--------  -----------------  -----
+repo/a/x  100.00% (+50.00%)  ++
+
+Top losses
+
+Package   Coverage Δ        Score
+--------  ----------------  -----
+repo/a/y  0.00% (-100.00%)  --
+
+Impacted Packages      Coverage Δ  Score
+---------------------  ----------  -----
+repo/a/… (2 packages)  66.67% (ø)
+
+repo/a
+
+Package   Coverage Δ         Score
+--------  -----------------  -----
+repo/a/y  0.00% (-100.00%)   --
+repo/a/x  100.00% (+50.00%)  ++
+
+Coverage history (last 1 commits)
+
+Package   Trend  Best               Worst
+--------  -----  -----------------  -----------------
+repo/a/y  █▁     100.00% (aaaaaaa)  100.00% (aaaaaaa)
+repo/a/x  ▁█     50.00% (aaaaaaa)   50.00% (aaaaaaa)
+Total     ▅▅     66.67% (aaaaaaa)   66.67% (aaaaaaa)
+
+Coverage of @org/a
+
+Changed File   Coverage Δ         Score
+-------------  -----------------  -----
+repo/a/y/b.go  0.00% (-100.00%)   --
+repo/a/x/a.go  100.00% (+50.00%)  ++
+Total          66.67% (ø)
+
+Changed files (no unit tests)
+
+Changed File   Coverage Δ         Total  Covered  Missed  Score
+-------------  -----------------  -----  -------  ------  -----
+repo/a/y/b.go  0.00% (-100.00%)   1      0 (-1)   1 (+1)  --
+repo/a/x/a.go  100.00% (+50.00%)  2      2 (+1)   0 (-1)  ++
+`
+	assert.Equal(t, expected, report.Text(false))
+}
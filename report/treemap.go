@@ -0,0 +1,305 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+)
+
+// TreemapOptions configures the treemap rendered by Treemap.
+type TreemapOptions struct {
+	// Width and Height are the dimensions of the SVG in pixels.
+	Width, Height int
+	// Old is the coverage to compare against. If it is set, packages are
+	// colored by their coverage delta instead of their coverage.
+	Old *coverage.Coverage
+	// Accessible uses a color-blind-safe blue and orange palette instead of
+	// red and green and marks coverage changes with ▲ and ▼ icons.
+	Accessible bool
+}
+
+const (
+	treemapHeader  = 16 // height of the label of a directory
+	treemapPadding = 2  // space between a directory and its children
+)
+
+// treemapNode is a directory in the package tree. If the directory is a
+// package itself, pkg is its coverage.
+type treemapNode struct {
+	name     string // path relative to the parent node
+	path     string // full package path
+	pkg      *coverage.Coverage
+	children []*treemapNode
+	size     int64 // total number of statements of the node and its children
+}
+
+// Treemap renders the packages of cov as SVG treemap. Each package is a
+// rectangle whose area is proportional to its number of statements and
+// which is nested into the rectangles of its parent directories. Packages
+// are colored by coverage (red to green) or, if opts.Old is set, by their
+// coverage delta (red for decrease, gray for no change, green for increase).
+func Treemap(cov *coverage.Coverage, opts TreemapOptions) string {
+	t := &treemap{svg: new(strings.Builder), opts: opts}
+	if opts.Old != nil {
+		t.oldPkgs = opts.Old.ByPackage()
+	}
+
+	w, h := float64(opts.Width), float64(opts.Height)
+	fmt.Fprintf(t.svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+"\n", opts.Width, opts.Height, opts.Width, opts.Height)
+	fmt.Fprintf(t.svg, `<rect width="%d" height="%d" fill="#fff"/>`+"\n", opts.Width, opts.Height)
+
+	root := newTreemapTree(cov.ByPackage())
+	if root.size > 0 {
+		t.layout(root, 0, 0, w, h)
+	}
+
+	t.svg.WriteString("</svg>\n")
+	return t.svg.String()
+}
+
+type treemap struct {
+	svg     *strings.Builder
+	opts    TreemapOptions
+	oldPkgs map[string]*coverage.Coverage
+}
+
+// newTreemapTree builds the directory tree of the given packages. Packages
+// without statements are omitted and directories with a single child and
+// no own package are merged into their child (e.g. "github.com/fgrosse").
+func newTreemapTree(pkgs map[string]*coverage.Coverage) *treemapNode {
+	root := &treemapNode{}
+	for _, name := range sortedKeys(pkgs) {
+		if pkgs[name].TotalStmt == 0 {
+			continue
+		}
+
+		n := root
+		for _, segment := range strings.Split(name, "/") {
+			n = n.child(segment)
+		}
+		n.pkg = pkgs[name]
+	}
+
+	root.compact()
+	return root
+}
+
+func (n *treemapNode) child(name string) *treemapNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+
+	c := &treemapNode{name: name, path: strings.TrimPrefix(n.path+"/"+name, "/")}
+	n.children = append(n.children, c)
+	return c
+}
+
+// compact merges directories without own package into their only child and
+// computes the sizes of all nodes.
+func (n *treemapNode) compact() {
+	for _, c := range n.children {
+		c.compact()
+	}
+
+	for n.pkg == nil && len(n.children) == 1 {
+		c := n.children[0]
+		n.name = strings.TrimPrefix(n.name+"/"+c.name, "/")
+		n.path, n.pkg, n.children = c.path, c.pkg, c.children
+	}
+
+	n.size = 0
+	if n.pkg != nil {
+		n.size = n.pkg.TotalStmt
+	}
+	for _, c := range n.children {
+		n.size += c.size
+	}
+}
+
+// layout draws the node into the given rectangle.
+func (t *treemap) layout(n *treemapNode, x, y, w, h float64) {
+	if len(n.children) == 0 {
+		t.drawPackage(n.path, n.name, n.pkg, x, y, w, h)
+		return
+	}
+
+	fmt.Fprintf(t.svg, `<g><title>%s</title>`, html.EscapeString(n.path))
+	fmt.Fprintf(t.svg, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#eee" stroke="#fff"/>`, x, y, w, h)
+
+	header := 0.0
+	if h > 2*treemapHeader && w > 20 {
+		header = treemapHeader
+		t.drawLabel(n.name, x+3, y+12, w-6, "#333")
+	}
+	t.svg.WriteString("</g>\n")
+
+	items := slices.Clone(n.children)
+	if n.pkg != nil {
+		// The package of the directory itself is shown next to its children.
+		items = append(items, &treemapNode{name: ".", path: n.path, pkg: n.pkg, size: n.pkg.TotalStmt})
+	}
+
+	slices.SortStableFunc(items, func(a, b *treemapNode) int {
+		return cmp.Compare(b.size, a.size)
+	})
+
+	p := math.Min(treemapPadding, math.Min(w, h)/4)
+	t.split(items, x+p, y+header+p, w-2*p, h-header-2*p)
+}
+
+// split distributes the rectangle among the items (sorted by size in
+// descending order) by recursively dividing them into two groups of about
+// the same size and splitting the rectangle along its longer side.
+func (t *treemap) split(items []*treemapNode, x, y, w, h float64) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	if len(items) == 1 {
+		t.layout(items[0], x, y, w, h)
+		return
+	}
+
+	var total int64
+	for _, n := range items {
+		total += n.size
+	}
+
+	k, sum := 0, int64(0)
+	for k < len(items)-1 && 2*(sum+items[k].size) <= total {
+		sum += items[k].size
+		k++
+	}
+	if k == 0 {
+		sum, k = items[0].size, 1
+	}
+
+	ratio := float64(sum) / float64(total)
+	if w >= h {
+		t.split(items[:k], x, y, w*ratio, h)
+		t.split(items[k:], x+w*ratio, y, w*(1-ratio), h)
+	} else {
+		t.split(items[:k], x, y, w, h*ratio)
+		t.split(items[k:], x, y+h*ratio, w, h*(1-ratio))
+	}
+}
+
+func (t *treemap) drawPackage(path, name string, pkg *coverage.Coverage, x, y, w, h float64) {
+	percent := pkg.Percent()
+	color, textColor := coverageColor(percent), "#fff"
+	if t.opts.Accessible {
+		color, textColor = accessibleCoverageColor(percent)
+	}
+
+	tooltip := fmt.Sprintf("%s: %s of %d statements", path, formatPercent(percent), pkg.TotalStmt)
+	if t.oldPkgs != nil {
+		oldPercent := coverageValues(t.oldPkgs[path]).Percent
+		delta := percent - oldPercent
+		switch {
+		case t.opts.Accessible:
+			color, textColor = accessibleDeltaColor(delta), "#000"
+			tooltip += fmt.Sprintf(" (%s)", accessibleDeltaText(percent, oldPercent))
+			name = deltaIcon(delta) + name
+		default:
+			color = deltaColor(delta)
+			tooltip += fmt.Sprintf(" (%s)", formatDelta(delta))
+		}
+	}
+
+	fmt.Fprintf(t.svg, `<g><title>%s</title>`, html.EscapeString(tooltip))
+	fmt.Fprintf(t.svg, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" stroke="#fff"/>`, x, y, w, h, color)
+	if h > 14 {
+		t.drawLabel(name, x+3, y+12, w-6, textColor)
+	}
+	t.svg.WriteString("</g>\n")
+}
+
+// drawLabel draws the text if at least a few characters fit into the given
+// width and truncates it if necessary.
+func (t *treemap) drawLabel(text string, x, y, w float64, color string) {
+	maxChars := int(w / 7)
+	if maxChars < 3 {
+		return
+	}
+
+	runes := []rune(text)
+	if len(runes) > maxChars {
+		text = string(runes[:maxChars-1]) + "…"
+	}
+
+	fmt.Fprintf(t.svg, `<text x="%.1f" y="%.1f" fill="%s">%s</text>`, x, y, color, html.EscapeString(text))
+}
+
+// coverageColor returns a color between red (0%) and green (100%).
+func coverageColor(percent float64) string {
+	return fmt.Sprintf("hsl(%.0f,65%%,40%%)", percent*1.2)
+}
+
+// deltaColor returns gray for unchanged coverage and a red or green color
+// whose intensity grows with the coverage change up to 10 percentage points.
+func deltaColor(delta float64) string {
+	delta = round(delta, 2)
+	if delta == 0 {
+		return "#9f9f9f"
+	}
+
+	hue := 120.0
+	if delta < 0 {
+		hue = 0
+	}
+
+	intensity := math.Min(math.Abs(delta), 10) / 10
+	return fmt.Sprintf("hsl(%.0f,65%%,%.0f%%)", hue, 65-30*intensity)
+}
+
+// accessibleCoverageColor returns a blue whose lightness decreases with the
+// coverage, together with a text color that is readable on it.
+func accessibleCoverageColor(percent float64) (color, textColor string) {
+	lightness := 90 - 0.55*percent
+	textColor = "#fff"
+	if lightness > 55 {
+		textColor = "#000"
+	}
+
+	return fmt.Sprintf("hsl(210,70%%,%.0f%%)", lightness), textColor
+}
+
+// accessibleDeltaColor is like deltaColor but uses orange for decreases and
+// blue for increases, which can be told apart with all common forms of
+// color blindness.
+func accessibleDeltaColor(delta float64) string {
+	delta = round(delta, 2)
+	if delta == 0 {
+		return "#9f9f9f"
+	}
+
+	hue := 210.0
+	if delta < 0 {
+		hue = 30
+	}
+
+	intensity := math.Min(math.Abs(delta), 10) / 10
+	return fmt.Sprintf("hsl(%.0f,80%%,%.0f%%)", hue, 80-30*intensity)
+}
+
+// deltaIcon returns an arrow that shows the direction of the coverage change
+// so it does not need to be derived from the color.
+func deltaIcon(delta float64) string {
+	delta = round(delta, 2)
+	switch {
+	case delta > 0:
+		return "▲ "
+	case delta < 0:
+		return "▼ "
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,90 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTreemapTree(t *testing.T) {
+	cov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/a/a.go:1.1,2.2 3 1
+example.com/a/b/b.go:1.1,2.2 1 0
+example.com/c/d/d.go:1.1,2.2 2 1
+example.com/empty/e.go:1.1,2.2 0 0
+`), nil)
+	require.NoError(t, err)
+
+	root := newTreemapTree(cov.ByPackage())
+	assert.Equal(t, "example.com", root.name)
+	assert.Nil(t, root.pkg)
+	assert.Equal(t, int64(6), root.size)
+	require.Len(t, root.children, 2)
+
+	a, c := root.children[0], root.children[1]
+	assert.Equal(t, "a", a.name)
+	assert.Equal(t, "example.com/a", a.path)
+	assert.NotNil(t, a.pkg)
+	assert.Equal(t, int64(4), a.size)
+	require.Len(t, a.children, 1)
+	assert.Equal(t, "example.com/a/b", a.children[0].path)
+
+	assert.Equal(t, "c/d", c.name)
+	assert.Equal(t, "example.com/c/d", c.path)
+	assert.Empty(t, c.children)
+}
+
+func TestTreemap(t *testing.T) {
+	oldCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/a/a.go:1.1,2.2 3 0
+example.com/b/b.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/a/a.go:1.1,2.2 3 1
+example.com/b/b.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	svg := Treemap(newCov, TreemapOptions{Width: 400, Height: 200})
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="400" height="200"`))
+	assert.Contains(t, svg, `<title>example.com/a: 100.00% of 3 statements</title><rect x="2.0" y="18.0" width="297.0" height="180.0" fill="hsl(120,65%,40%)"`)
+	assert.Contains(t, svg, `<title>example.com/b: 100.00% of 1 statements</title><rect x="299.0" y="18.0" width="99.0" height="180.0"`)
+
+	svg = Treemap(newCov, TreemapOptions{Width: 400, Height: 200, Old: oldCov})
+	assert.Contains(t, svg, `<title>example.com/a: 100.00% of 3 statements (+100.00%)</title><rect x="2.0" y="18.0" width="297.0" height="180.0" fill="hsl(120,65%,35%)"`)
+	assert.Contains(t, svg, `<title>example.com/b: 100.00% of 1 statements (ø)</title><rect x="299.0" y="18.0" width="99.0" height="180.0" fill="#9f9f9f"`)
+}
+
+func TestDeltaColor(t *testing.T) {
+	assert.Equal(t, "#9f9f9f", deltaColor(0.001))
+	assert.Equal(t, "hsl(0,65%,50%)", deltaColor(-5))
+	assert.Equal(t, "hsl(120,65%,35%)", deltaColor(25))
+}
+
+func TestTreemap_Accessible(t *testing.T) {
+	oldCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/a/a.go:1.1,2.2 3 1
+example.com/b/b.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.Parse(strings.NewReader(`mode: set
+example.com/a/a.go:1.1,2.2 3 0
+example.com/b/b.go:1.1,2.2 1 1
+`), nil)
+	require.NoError(t, err)
+
+	svg := Treemap(newCov, TreemapOptions{Width: 400, Height: 200, Accessible: true})
+	assert.Contains(t, svg, `fill="hsl(210,70%,90%)" stroke="#fff"/><text x="5.0" y="30.0" fill="#000">a</text>`)
+	assert.Contains(t, svg, `fill="hsl(210,70%,35%)" stroke="#fff"/><text x="302.0" y="30.0" fill="#fff">b</text>`)
+
+	svg = Treemap(newCov, TreemapOptions{Width: 400, Height: 200, Old: oldCov, Accessible: true})
+	assert.Contains(t, svg, `<title>example.com/a: 0.00% of 3 statements (▼ 100.00 pp)</title>`)
+	assert.Contains(t, svg, `fill="hsl(30,80%,50%)" stroke="#fff"/><text x="5.0" y="30.0" fill="#000">▼ a</text>`)
+	assert.Contains(t, svg, `fill="#9f9f9f" stroke="#fff"/><text x="302.0" y="30.0" fill="#000">b</text>`)
+}
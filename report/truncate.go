@@ -0,0 +1,148 @@
+package report
+
+import (
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// GitHubCommentMaxLength is the maximum number of characters of a comment on
+// GitHub. Longer comments are rejected by the API.
+const GitHubCommentMaxLength = 65536
+
+// maxTruncatedTestFiles is the number of changed test files that are still
+// listed once the report needs to be truncated.
+const maxTruncatedTestFiles = 10
+
+// truncatedSuffix is appended if the report still exceeds its MaxLength after
+// all details have been removed.
+const truncatedSuffix = "\n\n_The report was cut off because it exceeded the maximum length._"
+
+// truncate renders the data with progressively less details until the result
+// fits into the MaxLength of the report. It first drops files with unchanged
+// coverage, then shortens the list of test files, then halves the package and
+// file tables and finally removes all optional sections. If the result is
+// still too long, it is cut off.
+func (r *Report) truncate(tmpl *template.Template, data TemplateData) (string, error) {
+	data.Truncated = true
+	data.MaxLength = r.MaxLength
+
+	var out string
+	render := func() (bool, error) {
+		var err error
+		out, err = executeTemplate(tmpl, data)
+		return err == nil && utf8.RuneCountInString(out) <= r.MaxLength, err
+	}
+
+	for _, step := range []func(*TemplateData) bool{dropUnchangedFiles, summarizeTestFiles} {
+		if !step(&data) {
+			continue
+		}
+		if ok, err := render(); ok || err != nil {
+			return out, err
+		}
+	}
+
+	for halveTables(&data) {
+		if ok, err := render(); ok || err != nil {
+			return out, err
+		}
+	}
+
+	dropOptionalSections(&data)
+	if ok, err := render(); ok || err != nil {
+		return out, err
+	}
+
+	if utf8.RuneCountInString(truncatedSuffix) > r.MaxLength {
+		return cutLines(out, "", r.MaxLength), nil
+	}
+
+	return cutLines(out, truncatedSuffix, r.MaxLength), nil
+}
+
+// closeDetails closes a <details> element that was opened before the report
+// was cut off.
+const closeDetails = "\n\n</details>"
+
+// cutLines returns the longest prefix of complete lines of out that fits
+// into maxLength characters together with the suffix and the closing tags of
+// all <details> elements that are still open at the end of the prefix. Cutting
+// at line boundaries ensures that no table row or code span is split.
+func cutLines(out, suffix string, maxLength int) string {
+	closeLength := utf8.RuneCountInString(closeDetails)
+	suffixLength := utf8.RuneCountInString(suffix)
+
+	var end, length, open, bestEnd, bestOpen int
+	for _, line := range strings.SplitAfter(out, "\n") {
+		end += len(line)
+		length += utf8.RuneCountInString(line)
+		open += strings.Count(line, "<details>") - strings.Count(line, "</details>")
+		if length+open*closeLength+suffixLength > maxLength {
+			break
+		}
+		bestEnd, bestOpen = end, open
+	}
+
+	return strings.TrimRight(out[:bestEnd], "\n") + strings.Repeat(closeDetails, bestOpen) + suffix
+}
+
+func dropUnchangedFiles(data *TemplateData) bool {
+	files := make([]CoverageDelta, 0, len(data.CodeFiles))
+	for _, f := range data.CodeFiles {
+		if f.Status != "unchanged" {
+			files = append(files, f)
+		}
+	}
+
+	omitted := len(data.CodeFiles) - len(files)
+	data.CodeFiles = files
+	data.OmittedFiles += omitted
+	return omitted > 0
+}
+
+func summarizeTestFiles(data *TemplateData) bool {
+	if len(data.TestFiles) <= maxTruncatedTestFiles {
+		return false
+	}
+
+	data.OmittedTestFiles += len(data.TestFiles) - maxTruncatedTestFiles
+	data.TestFiles = data.TestFiles[:maxTruncatedTestFiles]
+	return true
+}
+
+// halveTables removes the second half of the rows of the package and file
+// tables. It returns false if all of them have at most one row left.
+func halveTables(data *TemplateData) bool {
+	changed := false
+	if n := len(data.Directories); n > 1 {
+		for _, d := range data.Directories[(n+1)/2:] {
+			data.OmittedPackages += len(d.Packages)
+		}
+		data.Directories = data.Directories[:(n+1)/2]
+		changed = true
+	}
+	if n := len(data.Packages); n > 1 && len(data.Directories) == 0 {
+		data.OmittedPackages += n - (n+1)/2
+		data.Packages = data.Packages[:(n+1)/2]
+		changed = true
+	}
+	if n := len(data.CodeFiles); n > 1 {
+		data.OmittedFiles += n - (n+1)/2
+		data.CodeFiles = data.CodeFiles[:(n+1)/2]
+		changed = true
+	}
+
+	return changed
+}
+
+func dropOptionalSections(data *TemplateData) {
+	data.TopGains, data.TopLosses = nil, nil
+	data.Components = nil
+	data.IndirectPackages = nil
+	data.History = nil
+	data.Owners = nil
+	for i := range data.Directories {
+		data.Directories[i].Rollup = false
+	}
+}
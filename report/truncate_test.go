@@ -0,0 +1,78 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fgrosse/go-coverage-report/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Markdown_MaxLength(t *testing.T) {
+	oldCov, err := coverage.ParseFile("testdata/01-old-coverage.txt", nil)
+	require.NoError(t, err)
+
+	newCov, err := coverage.ParseFile("testdata/01-new-coverage.txt", nil)
+	require.NoError(t, err)
+
+	changedFiles, err := ParseChangedFiles("testdata/01-changed-files.json", "github.com/fgrosse/prioqueue")
+	require.NoError(t, err)
+
+	for i := 1; i <= 15; i++ {
+		changedFiles = append(changedFiles, fmt.Sprintf("github.com/fgrosse/prioqueue/heap_%02d_test.go", i))
+	}
+
+	report := New(oldCov, newCov, changedFiles)
+	full := report.Markdown()
+
+	t.Run("fits", func(t *testing.T) {
+		report.MaxLength = utf8.RuneCountInString(full)
+		assert.Equal(t, full, report.Markdown())
+	})
+
+	t.Run("omits details", func(t *testing.T) {
+		report.MaxLength = 1552
+		report.Metadata.FullReportURL = "https://example.com/report"
+		actual := report.Markdown()
+
+		assert.LessOrEqual(t, utf8.RuneCountInString(actual), report.MaxLength)
+		assert.Contains(t, actual, "> Some details were omitted to keep this report within 1552 characters. See the [full report](https://example.com/report).\n")
+		assert.Contains(t, actual, "| github.com/fgrosse/prioqueue/min_heap.go |")
+		assert.NotContains(t, actual, "| github.com/fgrosse/prioqueue/foo/bar/baz.go |")
+		assert.Contains(t, actual, "| _and 1 more_ |\n")
+		assert.Contains(t, actual, "- github.com/fgrosse/prioqueue/heap_10_test.go\n- _and 5 more_\n")
+		assert.NotContains(t, actual, "heap_11_test.go")
+		assert.NotContains(t, actual, truncatedSuffix)
+	})
+
+	t.Run("cut off", func(t *testing.T) {
+		report.MaxLength = 300
+		actual := report.Markdown()
+
+		assert.LessOrEqual(t, utf8.RuneCountInString(actual), 300)
+		assert.True(t, strings.HasPrefix(actual, "### Merging this branch will **decrease** overall coverage\n"))
+		assert.True(t, strings.HasSuffix(actual, "See the [full report](https://example.com/report)."+truncatedSuffix))
+	})
+
+	t.Run("cut off within details", func(t *testing.T) {
+		report.MaxLength = 750
+		actual := report.Markdown()
+
+		assert.LessOrEqual(t, utf8.RuneCountInString(actual), 750)
+		assert.Equal(t, 1, strings.Count(actual, "<details>"))
+		assert.True(t, strings.HasSuffix(actual, "|---------|\n\n</details>"+truncatedSuffix))
+		for _, line := range strings.Split(actual, "\n") {
+			if strings.HasPrefix(line, "|") {
+				assert.True(t, strings.HasSuffix(line, "|"), line)
+			}
+		}
+	})
+
+	t.Run("cut off without suffix", func(t *testing.T) {
+		report.MaxLength = 50
+		assert.Equal(t, "", report.Markdown())
+	})
+}
@@ -34,7 +34,9 @@ You can use the following environment variables to configure the script:
 - ROOT_PACKAGE: The import path of the tested repository to add as a prefix to all paths of the changed files (optional)
 - TRIM_PACKAGE: Trim a prefix in the \"Impacted Packages\" column of the markdown report (optional)
 - EXCLUDE: Exclude files matching the given regular expression from the report (optional)
+- TEMPLATE: Path to a Go text/template file used to render the report (optional)
 - SKIP_COMMENT: Skip creating or updating the pull request comment (default: false)
+- MAX_LENGTH: The maximum number of characters of the comment. Larger reports omit details and link to the full report in the job summary (optional, requires a version of go-coverage-report with the -max-length flag)
 "
 
 if [[ $# != 3 ]]; then
@@ -55,6 +57,7 @@ COVERAGE_FILE_NAME=${COVERAGE_FILE_NAME:-coverage.txt}
 OLD_COVERAGE_PATH=.github/outputs/old-coverage.txt
 NEW_COVERAGE_PATH=.github/outputs/new-coverage.txt
 COVERAGE_COMMENT_PATH=.github/outputs/coverage-comment.md
+FULL_REPORT_PATH=.github/outputs/coverage-report.md
 CHANGED_FILES_PATH=${CHANGED_FILES_PATH:-.github/outputs/all_modified_files.json}
 SKIP_COMMENT=${SKIP_COMMENT:-false}
 
@@ -132,7 +135,11 @@ go-coverage-report \
     -root="$ROOT_PACKAGE" \
     -trim="$TRIM_PACKAGE" \
     ${EXCLUDE:+-exclude="$EXCLUDE"} \
+    ${TEMPLATE:+-template="$TEMPLATE"} \
     -metrics-file="$METRICS_OUTPUT" \
+    ${MAX_LENGTH:+-max-length="$MAX_LENGTH"} \
+    ${MAX_LENGTH:+-full-report="$FULL_REPORT_PATH"} \
+    ${MAX_LENGTH:+-full-report-url="${GITHUB_SERVER_URL:-https://github.com}/$GITHUB_REPOSITORY/actions/runs/$GITHUB_RUN_ID"} \
     "$OLD_COVERAGE_PATH" \
     "$NEW_COVERAGE_PATH" \
     "$CHANGED_FILES_PATH" \
@@ -141,6 +148,11 @@ go-coverage-report \
 cat "$METRICS_OUTPUT" >> "$GITHUB_OUTPUT"
 rm -f "$METRICS_OUTPUT"
 
+# Publish the complete report in the job summary if the comment had to be truncated
+if [ -s "$FULL_REPORT_PATH" ] && [ -n "${GITHUB_STEP_SUMMARY+x}" ] && ! cmp -s "$FULL_REPORT_PATH" "$COVERAGE_COMMENT_PATH"; then
+  cat "$FULL_REPORT_PATH" >> "$GITHUB_STEP_SUMMARY"
+fi
+
 if [ "$BASELINE_AVAILABLE" = "false" ]; then
   # Only prepend warning if there's actual coverage data to show
   if [ -s $COVERAGE_COMMENT_PATH ]; then